@@ -0,0 +1,35 @@
+package cache
+
+// Backend defines how the cache values are stored on disk
+type Backend string
+
+const (
+	// FileBackend stores all values of a cache in a single JSON file
+	FileBackend Backend = "file"
+	// KVBackend stores the values in an embedded key/value database, see KV
+	KVBackend Backend = "kv"
+)
+
+// SelectBackend returns the backend set using OMP_CACHE_BACKEND in the environment getenv reads from,
+// falling back to the configured one and the JSON file
+func SelectBackend(configured Backend, getenv func(string) string) Backend {
+	if backend := Backend(getenv("OMP_CACHE_BACKEND")); len(backend) != 0 {
+		configured = backend
+	}
+
+	switch configured {
+	case KVBackend:
+		return KVBackend
+	default:
+		return FileBackend
+	}
+}
+
+// New returns an uninitialized cache for the given backend
+func New(backend Backend) Cache {
+	if backend == KVBackend {
+		return &KV{}
+	}
+
+	return &File{}
+}
@@ -23,6 +23,12 @@ type Cache interface {
 	Delete(key string)
 }
 
+// Reusable is implemented by the caches a long running process can keep
+// in memory between renders, see Reuse
+type Reusable interface {
+	Reuse(persist bool)
+}
+
 type Context interface {
 	CacheKey() (string, bool)
 }
@@ -31,9 +37,18 @@ const (
 	FileName = "omp.cache"
 )
 
-var SessionFileName = fmt.Sprintf("%s.%s", FileName, sessionID())
+// SessionFileName is the cache file of the current shell session
+func SessionFileName() string {
+	return SessionFileNameFor(SessionID())
+}
+
+// SessionFileNameFor is the cache file of the shell session with the given ID
+func SessionFileNameFor(sessionID string) string {
+	return fmt.Sprintf("%s.%s", FileName, sessionID)
+}
 
-func sessionID() string {
+// SessionID identifies the current shell session
+func SessionID() string {
 	pid := os.Getenv("OMP_SESSION_ID")
 	if len(pid) == 0 {
 		log.Debug("OMP_SESSION_ID not set, using PID")
@@ -48,6 +63,7 @@ const (
 	TOGGLECACHE      = "toggle_cache"
 	PROMPTCOUNTCACHE = "prompt_count_cache"
 	ENGINECACHE      = "engine_cache"
+	PROMPTLINECACHE  = "prompt_line_cache"
 )
 
 type Entry struct {
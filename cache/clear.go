@@ -37,7 +37,7 @@ func Clear(cachePath string, force bool) ([]string, error) {
 		}
 
 		// don't delete the system cache file unless forced
-		if file.Name() == FileName {
+		if strings.TrimSuffix(file.Name(), kvFileSuffix) == FileName {
 			continue
 		}
 
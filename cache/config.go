@@ -3,6 +3,9 @@ package cache
 type Config struct {
 	Duration Duration `json:"duration,omitempty" toml:"duration,omitempty"`
 	Strategy Strategy `json:"strategy,omitempty" toml:"strategy,omitempty"`
+	// WatchFiles invalidates the cached value when one of the files
+	// matching these globs is added, removed or changes
+	WatchFiles []string `json:"watch_files,omitempty" toml:"watch_files,omitempty"`
 }
 
 type Strategy string
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Expires returns the moment the entry expires, entries without a TTL never expire
+func (c *Entry) Expires() (time.Time, bool) {
+	if c.TTL < 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(c.Timestamp+int64(c.TTL), 0), true
+}
+
+// Entries returns all entries in the cache, including the expired ones
+func Entries(backend Backend, cacheFilePath string) (map[string]*Entry, error) {
+	if backend == KVBackend {
+		return kvEntries(cacheFilePath + kvFileSuffix)
+	}
+
+	return fileEntries(cacheFilePath)
+}
+
+func fileEntries(cacheFilePath string) (map[string]*Entry, error) {
+	content, err := os.ReadFile(cacheFilePath)
+	if os.IsNotExist(err) {
+		return map[string]*Entry{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var list map[string]*Entry
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, err
+	}
+
+	for key, co := range list {
+		if co == nil {
+			delete(list, key)
+		}
+	}
+
+	return list, nil
+}
+
+// DeleteEntries removes the entries for which match returns true from the cache
+// and returns their keys
+func DeleteEntries(backend Backend, cacheFilePath string, match func(key string, entry *Entry) bool) ([]string, error) {
+	if backend == KVBackend {
+		return deleteKVEntries(cacheFilePath+kvFileSuffix, match)
+	}
+
+	var deleted []string
+
+	err := update(cacheFilePath, func(list map[string]*Entry) {
+		for key, co := range list {
+			if !match(key, co) {
+				continue
+			}
+
+			delete(list, key)
+			deleted = append(deleted, key)
+		}
+	})
+
+	return deleted, err
+}
+
+func deleteKVEntries(path string, match func(key string, entry *Entry) bool) ([]string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var deleted []string
+
+	err := updateKV(path, func(bucket *bolt.Bucket) error {
+		// keys can't be removed while iterating the bucket
+		err := bucket.ForEach(func(key, _ []byte) error {
+			co, ok := kvEntry(bucket, string(key))
+			if ok && match(string(key), co) {
+				deleted = append(deleted, string(key))
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, key := range deleted {
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// update rewrites the cache file after applying change, while holding the lock
+func update(cacheFilePath string, change func(list map[string]*Entry)) error {
+	unlock, err := lockFile(cacheFilePath + lockFileSuffix)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	list, err := fileEntries(cacheFilePath)
+	if err != nil {
+		return err
+	}
+
+	count := len(list)
+	change(list)
+
+	if count == len(list) {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(list, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(cacheFilePath, content)
+}
+
+// Prune removes the expired entries from all cache files, the session files that
+// no longer contain valid entries and the least used keys of the stats file, and returns what was removed
+func Prune(cachePath string) ([]string, error) {
+	files, err := os.ReadDir(cachePath)
+	if err != nil {
+		return []string{}, err
+	}
+
+	var removed []string
+	sessionFileName := SessionFileName()
+
+	// sum the stats recorded since the last prune and drop the least used keys
+	if keys, err := compactStats(filepath.Join(cachePath, statsFileName)); err == nil {
+		for _, key := range keys {
+			removed = append(removed, statsFileName+": "+key)
+		}
+	}
+
+	isExpired := func(_ string, co *Entry) bool {
+		return co.Expired()
+	}
+
+	for _, file := range files {
+		name := file.Name()
+
+		backend := FileBackend
+		if trimmed, ok := strings.CutSuffix(name, kvFileSuffix); ok {
+			backend = KVBackend
+			name = trimmed
+		}
+
+		if !isCacheFile(name) {
+			continue
+		}
+
+		path := filepath.Join(cachePath, name)
+
+		keys, err := DeleteEntries(backend, path, isExpired)
+		if err != nil {
+			continue
+		}
+
+		for _, key := range keys {
+			removed = append(removed, file.Name()+": "+key)
+		}
+
+		if name == FileName || name == sessionFileName {
+			continue
+		}
+
+		list, err := Entries(backend, path)
+		if err != nil || len(list) != 0 {
+			continue
+		}
+
+		if backend == KVBackend {
+			path += kvFileSuffix
+		}
+
+		if err := os.Remove(path); err == nil {
+			_ = os.Remove(path + lockFileSuffix)
+			removed = append(removed, path)
+		}
+	}
+
+	return removed, nil
+}
+
+// isCacheFile returns true for the device and session cache files
+func isCacheFile(name string) bool {
+	if name == FileName {
+		return true
+	}
+
+	session, ok := strings.CutPrefix(name, FileName+".")
+	if !ok || len(session) == 0 {
+		return false
+	}
+
+	// skip the config snapshots, stats, locks and temporary files
+	if strings.HasSuffix(name, lockFileSuffix) || name == statsFileName {
+		return false
+	}
+
+	return !strings.Contains(session, ".")
+}
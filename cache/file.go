@@ -3,15 +3,23 @@ package cache
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/LNKLEO/OMP/log"
 	"github.com/LNKLEO/OMP/maps"
 )
 
+const (
+	lockFileSuffix = ".lock"
+)
+
 type File struct {
 	cache         *maps.Concurrent
+	changed       *maps.Concurrent
+	deleted       *maps.Concurrent
 	cacheFilePath string
+	modTime       time.Time
 	dirty         bool
 	persist       bool
 }
@@ -20,8 +28,12 @@ func (fc *File) Init(cacheFilePath string, persist bool) {
 	defer log.Trace(time.Now(), cacheFilePath)
 
 	fc.cache = maps.NewConcurrent()
+	fc.changed = maps.NewConcurrent()
+	fc.deleted = maps.NewConcurrent()
 	fc.cacheFilePath = cacheFilePath
 	fc.persist = persist
+	fc.dirty = false
+	fc.modTime = fc.fileModTime()
 
 	log.Debug("loading cache file:", fc.cacheFilePath)
 
@@ -33,32 +45,155 @@ func (fc *File) Init(cacheFilePath string, persist bool) {
 		return
 	}
 
+	for key, co := range parseEntries(content) {
+		log.Debug("loading cache key:", key)
+		fc.cache.Set(key, co)
+	}
+}
+
+// parseEntries returns the entries in the cache file content that did not expire yet
+func parseEntries(content []byte) map[string]*Entry {
 	var list map[string]*Entry
 	if err := json.Unmarshal(content, &list); err != nil {
-		return
+		return nil
 	}
 
 	for key, co := range list {
-		if co.Expired() {
+		if co == nil || co.Expired() {
 			log.Debug("skipping expired cache key:", key)
-			continue
+			delete(list, key)
 		}
-
-		log.Debug("loading cache key:", key)
-		fc.cache.Set(key, co)
 	}
+
+	return list
 }
 
+// Close writes the cache to disk when it changed.
+//
+// Multiple shells share the same cache file, so the file is locked while writing
+// and only the keys this process set or deleted are applied to what's on disk,
+// keeping the changes other processes made in the meantime. The content is written
+// to a temporary file first and renamed over the cache file so readers never see a partial write.
 func (fc *File) Close() {
 	if !fc.persist || !fc.dirty {
 		return
 	}
 
-	cache := fc.cache.ToSimple()
+	defer log.Trace(time.Now(), fc.cacheFilePath)
+
+	unlock, err := lockFile(fc.cacheFilePath + lockFileSuffix)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	defer unlock()
+
+	cache := fc.merge()
+
+	dump, err := json.MarshalIndent(cache, "", "    ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if err := writeFileAtomic(fc.cacheFilePath, dump); err != nil {
+		log.Error(err)
+		return
+	}
+
+	// the merged entries are what's on disk now
+	fc.cache = maps.NewConcurrent()
+	for key, co := range cache {
+		fc.cache.Set(key, co)
+	}
+
+	fc.changed = maps.NewConcurrent()
+	fc.deleted = maps.NewConcurrent()
+	fc.dirty = false
+	fc.modTime = fc.fileModTime()
+}
+
+// Reuse prepares the cache for another render in the same process,
+// the file is loaded again when another process changed it
+func (fc *File) Reuse(persist bool) {
+	if fc.dirty || !fc.modTime.Equal(fc.fileModTime()) {
+		fc.Init(fc.cacheFilePath, persist)
+		return
+	}
+
+	fc.persist = persist
+}
+
+func (fc *File) fileModTime() time.Time {
+	info, err := os.Stat(fc.cacheFilePath)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+// merge applies the keys this process set or deleted to the entries currently on disk,
+// an entry another process wrote after it was set or deleted here is kept
+func (fc *File) merge() map[string]*Entry {
+	result := make(map[string]*Entry)
+
+	if content, err := os.ReadFile(fc.cacheFilePath); err == nil {
+		for key, co := range parseEntries(content) {
+			if deletedAt, ok := fc.deleted.Get(key); ok && deletedAt.(int64) >= co.Timestamp {
+				continue
+			}
+
+			result[key] = co
+		}
+	}
+
+	for key, value := range fc.changed.ToSimple() {
+		co, ok := value.(*Entry)
+		if !ok || co.Expired() {
+			continue
+		}
+
+		if current, ok := result[key]; ok && current.Timestamp > co.Timestamp {
+			continue
+		}
+
+		result[key] = co
+	}
+
+	return result
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := file.Name()
+
+	if _, err = file.Write(content); err == nil {
+		err = file.Sync()
+	}
 
-	if dump, err := json.MarshalIndent(cache, "", "    "); err == nil {
-		_ = os.WriteFile(fc.cacheFilePath, dump, 0o644)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
 	}
+
+	if err == nil {
+		err = os.Chmod(tmpPath, 0o644)
+	}
+
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
+	}
+
+	return err
 }
 
 // returns the value for the given key as long as
@@ -66,13 +201,16 @@ func (fc *File) Close() {
 func (fc *File) Get(key string) (string, bool) {
 	val, found := fc.cache.Get(key)
 	if !found {
+		record(key, false)
 		return "", false
 	}
 
-	if co, ok := val.(*Entry); ok {
+	if co, ok := val.(*Entry); ok && !co.Expired() {
+		record(key, true)
 		return co.Value, true
 	}
 
+	record(key, false)
 	return "", false
 }
 
@@ -84,17 +222,22 @@ func (fc *File) Set(key, value string, duration Duration) {
 		return
 	}
 
-	fc.cache.Set(key, &Entry{
+	co := &Entry{
 		Value:     value,
 		Timestamp: time.Now().Unix(),
 		TTL:       seconds,
-	})
+	}
 
+	fc.cache.Set(key, co)
+	fc.changed.Set(key, co)
+	fc.deleted.Delete(key)
 	fc.dirty = true
 }
 
 // delete the key from the cache
 func (fc *File) Delete(key string) {
 	fc.cache.Delete(key)
+	fc.changed.Delete(key)
+	fc.deleted.Set(key, time.Now().Unix())
 	fc.dirty = true
 }
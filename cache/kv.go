@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/LNKLEO/OMP/log"
+	"github.com/LNKLEO/OMP/maps"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	kvFileSuffix = ".db"
+)
+
+var (
+	kvBucket = []byte("cache")
+)
+
+// KV stores the values in an embedded key/value database next to the JSON cache file.
+//
+// Unlike File, it only reads the keys that are requested and only writes
+// the keys that changed, so the cost of a prompt doesn't grow with the amount
+// of cached values. Every read and write happens in a transaction, so an expired key
+// is only removed when it's still expired at the moment it gets deleted.
+type KV struct {
+	cache   *maps.Concurrent
+	changed *maps.Concurrent
+	deleted *maps.Concurrent
+	expired *maps.Concurrent
+	path    string
+	persist bool
+}
+
+func (kv *KV) Init(cacheFilePath string, persist bool) {
+	defer log.Trace(time.Now(), cacheFilePath)
+
+	kv.cache = maps.NewConcurrent()
+	kv.changed = maps.NewConcurrent()
+	kv.deleted = maps.NewConcurrent()
+	kv.expired = maps.NewConcurrent()
+	kv.path = cacheFilePath + kvFileSuffix
+	kv.persist = persist
+}
+
+func (kv *KV) Close() {
+	if !kv.persist {
+		return
+	}
+
+	defer log.Trace(time.Now(), kv.path)
+
+	changed := kv.changed.ToSimple()
+	deleted := kv.deleted.ToSimple()
+	expired := kv.expired.ToSimple()
+
+	if len(changed) == 0 && len(deleted) == 0 && len(expired) == 0 {
+		return
+	}
+
+	err := updateKV(kv.path, func(bucket *bolt.Bucket) error {
+		for key, value := range changed {
+			co, ok := value.(*Entry)
+			if !ok {
+				continue
+			}
+
+			content, err := json.Marshal(co)
+			if err != nil {
+				return err
+			}
+
+			if err := bucket.Put([]byte(key), content); err != nil {
+				return err
+			}
+		}
+
+		for key := range deleted {
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		// another process might have set the key again after it was read
+		for key := range expired {
+			co, ok := kvEntry(bucket, key)
+			if ok && !co.Expired() {
+				continue
+			}
+
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// Reuse prepares the cache for another render in the same process,
+// the values are read again as other processes might have changed them
+func (kv *KV) Reuse(persist bool) {
+	kv.Init(strings.TrimSuffix(kv.path, kvFileSuffix), persist)
+}
+
+// returns the value for the given key as long as
+// the duration is not expired
+func (kv *KV) Get(key string) (string, bool) {
+	if val, found := kv.cache.Get(key); found {
+		if co, ok := val.(*Entry); ok && !co.Expired() {
+			record(key, true)
+			return co.Value, true
+		}
+
+		record(key, false)
+		return "", false
+	}
+
+	if kv.deleted.Contains(key) || kv.expired.Contains(key) {
+		record(key, false)
+		return "", false
+	}
+
+	var co *Entry
+	var found bool
+
+	err := viewKV(kv.path, func(bucket *bolt.Bucket) error {
+		co, found = kvEntry(bucket, key)
+		return nil
+	})
+
+	if err != nil || !found {
+		record(key, false)
+		return "", false
+	}
+
+	if co.Expired() {
+		log.Debug("removing expired cache key:", key)
+		kv.expired.Set(key, true)
+		record(key, false)
+		return "", false
+	}
+
+	log.Debug("loading cache key:", key)
+	kv.cache.Set(key, co)
+
+	record(key, true)
+	return co.Value, true
+}
+
+// sets the value for the given key with a duration
+func (kv *KV) Set(key, value string, duration Duration) {
+	seconds := duration.Seconds()
+
+	if seconds == 0 {
+		return
+	}
+
+	co := &Entry{
+		Value:     value,
+		Timestamp: time.Now().Unix(),
+		TTL:       seconds,
+	}
+
+	kv.cache.Set(key, co)
+	kv.changed.Set(key, co)
+	kv.deleted.Delete(key)
+	kv.expired.Delete(key)
+}
+
+// delete the key from the cache
+func (kv *KV) Delete(key string) {
+	kv.cache.Delete(key)
+	kv.changed.Delete(key)
+	kv.expired.Delete(key)
+	kv.deleted.Set(key, true)
+}
+
+// openKV opens the database, waiting for the lock held by other processes at most lockTimeout
+func openKV(path string, readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{
+		Timeout:  lockTimeout,
+		ReadOnly: readOnly,
+	})
+
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, errLockTimeout
+	}
+
+	return db, err
+}
+
+// viewKV runs view in a read transaction, the database not existing yet is the same as it being empty
+func viewKV(path string, view func(bucket *bolt.Bucket) error) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	db, err := openKV(path, true)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(kvBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return view(bucket)
+	})
+}
+
+// updateKV runs change in a write transaction, nothing is written when change returns an error
+func updateKV(path string, change func(bucket *bolt.Bucket) error) error {
+	db, err := openKV(path, false)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(kvBucket)
+		if err != nil {
+			return err
+		}
+
+		return change(bucket)
+	})
+}
+
+func kvEntry(bucket *bolt.Bucket, key string) (*Entry, bool) {
+	content := bucket.Get([]byte(key))
+	if content == nil {
+		return nil, false
+	}
+
+	var co Entry
+	if err := json.Unmarshal(content, &co); err != nil {
+		return nil, false
+	}
+
+	return &co, true
+}
+
+// kvEntries returns all entries stored in the database of a KV cache
+func kvEntries(path string) (map[string]*Entry, error) {
+	entries := make(map[string]*Entry)
+
+	err := viewKV(path, func(bucket *bolt.Bucket) error {
+		return bucket.ForEach(func(key, _ []byte) error {
+			if co, ok := kvEntry(bucket, string(key)); ok {
+				entries[string(key)] = co
+			}
+
+			return nil
+		})
+	})
+
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+
+	return entries, err
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	// lockTimeout keeps a process holding on to the lock from blocking the prompt,
+	// the changes are not written when the lock can't be taken in time
+	lockTimeout       = 100 * time.Millisecond
+	lockRetryInterval = 5 * time.Millisecond
+)
+
+var errLockTimeout = errors.New("timed out waiting for the cache lock")
+
+// lockFile takes an exclusive lock on path, retrying until lockTimeout passed
+func lockFile(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		if locked {
+			return func() {
+				unlockFile(file)
+				file.Close()
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, errLockTimeout
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
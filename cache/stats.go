@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	statsFileName = FileName + ".stats"
+	// statsCompactSize is the size of the stats file after which saving sums its lines into one
+	statsCompactSize = 256 * 1024
+	// maxStatsKeys caps the keys kept when compacting, the least used ones are dropped
+	maxStatsKeys = 200
+)
+
+// Counter holds the amount of lookups for a cache key
+type Counter struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+var (
+	stats      = make(map[string]*Counter)
+	statsMutex sync.Mutex
+)
+
+func record(key string, hit bool) {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+
+	key = statsKey(key)
+
+	counter, ok := stats[key]
+	if !ok {
+		counter = &Counter{}
+		stats[key] = counter
+	}
+
+	if hit {
+		counter.Hits++
+		return
+	}
+
+	counter.Misses++
+}
+
+// statsKey groups the keys containing a folder, like the ones of segments cached per folder,
+// so the stats don't grow with every folder that gets visited
+func statsKey(key string) string {
+	index := strings.IndexAny(key, `/\`)
+	if index == -1 {
+		return key
+	}
+
+	if separator := strings.LastIndex(key[:index], "_"); separator != -1 {
+		return key[:separator]
+	}
+
+	return key
+}
+
+func statsFilePath() string {
+	return filepath.Join(Path(), statsFileName)
+}
+
+// Stats returns the hit and miss counters per key recorded during renders
+func Stats() (map[string]*Counter, error) {
+	content, err := os.ReadFile(statsFilePath())
+	if os.IsNotExist(err) {
+		return make(map[string]*Counter), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStats(content), nil
+}
+
+// parseStats sums the counters of every line in the stats file
+func parseStats(content []byte) map[string]*Counter {
+	result := make(map[string]*Counter)
+
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		var counters map[string]*Counter
+
+		// skip the lines that didn't get written completely
+		if err := json.Unmarshal(line, &counters); err != nil {
+			continue
+		}
+
+		for key, counter := range counters {
+			if counter == nil {
+				continue
+			}
+
+			current, ok := result[key]
+			if !ok {
+				current = &Counter{}
+				result[key] = current
+			}
+
+			current.Hits += counter.Hits
+			current.Misses += counter.Misses
+		}
+	}
+
+	return result
+}
+
+// SaveStats appends the counters recorded by this process to the stats file as a single line,
+// that way every prompt only writes what it recorded without reading the file.
+// The file is locked as compacting replaces it, lines appended meanwhile would get lost.
+func SaveStats() error {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+
+	if len(stats) == 0 {
+		return nil
+	}
+
+	line, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	path := statsFilePath()
+
+	unlock, err := lockFile(path + lockFileSuffix)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(line, '\n'))
+
+	var size int64
+	if info, statErr := file.Stat(); statErr == nil {
+		size = info.Size()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	stats = make(map[string]*Counter)
+
+	if size < statsCompactSize {
+		return nil
+	}
+
+	_, err = sumStats(path)
+	return err
+}
+
+// compactStats sums the lines of the stats file into one, keeping the most used keys,
+// and returns the keys that were dropped
+func compactStats(path string) ([]string, error) {
+	unlock, err := lockFile(path + lockFileSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	defer unlock()
+
+	return sumStats(path)
+}
+
+// sumStats does the work of compactStats, the caller holds the lock
+func sumStats(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+
+	total := parseStats(content)
+
+	if len(total) > maxStatsKeys {
+		keys := make([]string, 0, len(total))
+		for key := range total {
+			keys = append(keys, key)
+		}
+
+		slices.SortFunc(keys, func(a, b string) int {
+			return (total[b].Hits + total[b].Misses) - (total[a].Hits + total[a].Misses)
+		})
+
+		dropped = keys[maxStatsKeys:]
+		for _, key := range dropped {
+			delete(total, key)
+		}
+	}
+
+	line, err := json.Marshal(total)
+	if err != nil {
+		return nil, err
+	}
+
+	return dropped, writeFileAtomic(path, append(line, '\n'))
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// HashFiles returns a hash of the path, size and modification time of every file
+// matching the globs, relative globs are resolved against root and ** matches any amount of folders
+func HashFiles(root string, globs []string) string {
+	var matches []string
+
+	for _, glob := range globs {
+		if !filepath.IsAbs(glob) {
+			glob = filepath.Join(root, glob)
+		}
+
+		files, err := doublestar.FilepathGlob(glob)
+		if err != nil {
+			continue
+		}
+
+		matches = append(matches, files...)
+	}
+
+	slices.Sort(matches)
+	matches = slices.Compact(matches)
+
+	hash := sha256.New()
+
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", match, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
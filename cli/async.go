@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/log"
+	"github.com/LNKLEO/OMP/prompt"
+	"github.com/LNKLEO/OMP/runtime"
+	"github.com/LNKLEO/OMP/shell"
+	"github.com/LNKLEO/OMP/template"
+
+	"github.com/spf13/cobra"
+)
+
+var asyncPID int
+
+// asyncCmd refreshes an async segment in the background, it's started by the prompt itself
+var asyncCmd = &cobra.Command{
+	Use:    "async [segment]",
+	Short:  "Refresh an async segment",
+	Long:   "Refresh an async segment and notify the shell to repaint the prompt.",
+	Args:   cobra.ExactArgs(1),
+	Hidden: true,
+	Run: func(_ *cobra.Command, args []string) {
+		flags := &runtime.Flags{
+			Config:    configFlag,
+			PWD:       pwd,
+			Shell:     shellName,
+			IsPrimary: true,
+			SaveCache: true,
+			Refresh:   true,
+		}
+
+		eng := prompt.New(flags)
+
+		refreshed := eng.Refresh(args[0])
+
+		template.SaveCache()
+		eng.Env.Close()
+
+		if !refreshed {
+			log.Debug("no async segment found:", args[0])
+			return
+		}
+
+		notifyShell(shellName, asyncPID)
+	},
+}
+
+func init() {
+	asyncCmd.Flags().StringVar(&pwd, "pwd", "", "current working directory")
+	asyncCmd.Flags().StringVar(&shellName, "shell", "", "the shell to refresh for")
+	asyncCmd.Flags().IntVar(&asyncPID, "async-pid", 0, "the shell process to signal")
+	RootCmd.AddCommand(asyncCmd)
+}
+
+// notifyShell lets the shell know a refreshed value is available so it can repaint the prompt,
+// zsh traps a signal while PowerShell watches a file in the cache folder
+func notifyShell(sh string, pid int) {
+	switch sh {
+	case shell.PWSH, shell.PWSH5:
+		path := filepath.Join(cache.Path(), cache.SessionFileName()+".async")
+		if err := os.WriteFile(path, []byte(strconv.FormatInt(time.Now().UnixNano(), 10)), 0o644); err != nil {
+			log.Error(err)
+		}
+	default:
+		if pid == 0 {
+			return
+		}
+
+		if err := signalRepaint(pid); err != nil {
+			log.Error(err)
+		}
+	}
+}
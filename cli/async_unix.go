@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cli
+
+import "syscall"
+
+func signalRepaint(pid int) error {
+	return syscall.Kill(pid, syscall.SIGUSR1)
+}
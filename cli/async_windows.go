@@ -0,0 +1,6 @@
+package cli
+
+// signalRepaint is not supported, the shells on Windows are notified using a file
+func signalRepaint(_ int) error {
+	return nil
+}
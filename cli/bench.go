@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/LNKLEO/OMP/config"
+	"github.com/LNKLEO/OMP/prompt"
+	"github.com/LNKLEO/OMP/runtime"
+
+	json "github.com/goccy/go-json"
+	"github.com/spf13/cobra"
+)
+
+var iterations int
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark the primary prompt",
+	Long: `Benchmark the primary prompt.
+
+Renders the primary prompt multiple times, with and without the session cache,
+using an empty cache that is removed afterwards and without async segments,
+and reports the p50/p95/p99 latency of the engine and every segment,
+together with the slowest commands that were executed.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if iterations < 1 {
+			fmt.Println("count must be at least 1")
+			os.Exit(1)
+		}
+
+		if err := runBench(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runBench renders using a throwaway cache, the benchmark must not change the state of the user's prompt
+func runBench() error {
+	cacheDir, err := os.MkdirTemp("", "omp-bench-")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(cacheDir)
+
+	if err := os.Setenv("OMP_CACHE_DIR", cacheDir); err != nil {
+		return err
+	}
+
+	flags := &runtime.Flags{
+		Config:        config.Path(configFlag),
+		PWD:           pwd,
+		Shell:         shellName,
+		TerminalWidth: terminalWidth,
+		Plain:         true,
+	}
+
+	result := prompt.Bench(flags, iterations)
+
+	if format != prompt.JSONFormat {
+		printBenchResult(result)
+		return nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+func printBenchResult(result *prompt.BenchResult) {
+	fmt.Printf("Iterations: %d\n\n", result.Iterations)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Segment\tCache\tp50 (ms)\tp95 (ms)\tp99 (ms)")
+
+	writeRun := func(run *prompt.BenchRun, cache string) {
+		for _, stats := range append(run.Segments, run.Total) {
+			fmt.Fprintf(writer, "%s\t%s\t%.3f\t%.3f\t%.3f\n", stats.Name, cache, stats.P50, stats.P95, stats.P99)
+		}
+	}
+
+	writeRun(result.Cached, "on")
+	writeRun(result.Uncached, "off")
+	_ = writer.Flush()
+
+	if len(result.Commands) == 0 {
+		return
+	}
+
+	fmt.Println("\nSlowest commands:")
+
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, command := range result.Commands {
+		fmt.Fprintf(writer, "  %s\t%d calls\t%.3f ms\n", strings.TrimSpace(command.Command), command.Calls, command.Max)
+	}
+
+	_ = writer.Flush()
+}
+
+func init() {
+	benchCmd.Flags().IntVarP(&iterations, "count", "n", 20, "number of times to render the prompt")
+	benchCmd.Flags().StringVar(&pwd, "pwd", "", "current working directory")
+	benchCmd.Flags().StringVar(&shellName, "shell", "", "the shell to benchmark for")
+	benchCmd.Flags().IntVarP(&terminalWidth, "terminal-width", "w", 0, "width of the terminal")
+	benchCmd.Flags().StringVar(&format, "format", "", "output format, use json for structured output")
+	RootCmd.AddCommand(benchCmd)
+}
@@ -2,15 +2,27 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/config"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	session bool
+	prefix  string
+)
+
 // getCmd represents the get command
 var getCache = &cobra.Command{
-	Use:   "cache [path|clear|edit]",
+	Use:   "cache [path|clear|list|get|delete|prune|stats]",
 	Short: "Interact with the OMP cache",
 	Long: `Interact with the OMP cache.
 
@@ -18,12 +30,21 @@ You can do the following:
 
 - path: list cache path
 - clear: remove all cache values
-- edit: edit cache values`,
+- list: list the cache keys, use --session for the current session's cache
+- get KEY: print the value of a cache key
+- delete KEY: remove a cache key, or all keys starting with --prefix
+- prune: remove expired cache values and unused session cache files
+- stats: show the cache hits and misses recorded while rendering`,
 	ValidArgs: []string{
 		"path",
 		"clear",
+		"list",
+		"get",
+		"delete",
+		"prune",
+		"stats",
 	},
-	Args: NoArgsOrOneValidArg,
+	Args: cacheArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			_ = cmd.Help()
@@ -43,10 +64,193 @@ You can do the following:
 			for _, file := range deletedFiles {
 				fmt.Println("removed cache file:", file)
 			}
+		case "list":
+			listCache()
+		case "get":
+			getCacheValue(args[1])
+		case "delete":
+			deleteCacheValues(args[1:])
+		case "prune":
+			removed, err := cache.Prune(cache.Path())
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			for _, value := range removed {
+				fmt.Println("removed:", value)
+			}
+		case "stats":
+			printCacheStats()
 		}
 	},
 }
 
 func init() {
+	getCache.Flags().BoolVar(&session, "session", false, "use the cache of the current session")
+	getCache.Flags().StringVar(&prefix, "prefix", "", "delete all keys starting with this prefix")
 	RootCmd.AddCommand(getCache)
 }
+
+func cacheArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	if err := cobra.OnlyValidArgs(cmd, args[:1]); err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "delete" && len(prefix) != 0:
+		return cobra.ExactArgs(1)(cmd, args)
+	case args[0] == "get", args[0] == "delete":
+		return cobra.ExactArgs(2)(cmd, args)
+	default:
+		return cobra.ExactArgs(1)(cmd, args)
+	}
+}
+
+// cacheBackend returns the backend in use, so the commands
+// read and write the same values as the prompt
+func cacheBackend() cache.Backend {
+	cfg := config.Load(config.Path(configFlag), "")
+	return cache.SelectBackend(cfg.CacheBackend, os.Getenv)
+}
+
+func cacheFile() string {
+	if session {
+		return filepath.Join(cache.Path(), cache.SessionFileName())
+	}
+
+	return filepath.Join(cache.Path(), cache.FileName)
+}
+
+func listCache() {
+	entries, err := cache.Entries(cacheBackend(), cacheFile())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tSIZE\tTTL\tEXPIRES")
+
+	for _, key := range keys {
+		entry := entries[key]
+
+		remaining := "infinite"
+		expires := "never"
+
+		if moment, ok := entry.Expires(); ok {
+			expires = moment.Format(time.DateTime)
+			remaining = "expired"
+
+			if left := time.Until(moment).Round(time.Second); left > 0 {
+				remaining = left.String()
+			}
+		}
+
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\n", key, len(entry.Value), remaining, expires)
+	}
+
+	_ = writer.Flush()
+}
+
+func getCacheValue(key string) {
+	entries, err := cache.Entries(cacheBackend(), cacheFile())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	entry, ok := entries[key]
+	if !ok || entry.Expired() {
+		fmt.Printf("cache key %s not found\n", key)
+		os.Exit(1)
+	}
+
+	fmt.Println(entry.Value)
+}
+
+func deleteCacheValues(args []string) {
+	match := func(key string, _ *cache.Entry) bool {
+		if len(args) != 0 {
+			return key == args[0]
+		}
+
+		return strings.HasPrefix(key, prefix)
+	}
+
+	deleted, err := cache.DeleteEntries(cacheBackend(), cacheFile(), match)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if len(deleted) == 0 {
+		fmt.Println("no matching cache keys found")
+		return
+	}
+
+	slices.Sort(deleted)
+
+	for _, key := range deleted {
+		fmt.Println("removed cache key:", key)
+	}
+}
+
+func printCacheStats() {
+	stats, err := cache.Stats()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	keys := make([]string, 0, len(stats))
+	var hits, misses int
+
+	for key, counter := range stats {
+		keys = append(keys, key)
+		hits += counter.Hits
+		misses += counter.Misses
+	}
+
+	// most used keys first
+	slices.SortFunc(keys, func(a, b string) int {
+		totalA := stats[a].Hits + stats[a].Misses
+		totalB := stats[b].Hits + stats[b].Misses
+		if totalA != totalB {
+			return totalB - totalA
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tHITS\tMISSES\tHIT RATE")
+
+	row := func(key string, hits, misses int) {
+		rate := 0.0
+		if total := hits + misses; total != 0 {
+			rate = float64(hits) / float64(total) * 100
+		}
+
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%.1f%%\n", key, hits, misses, rate)
+	}
+
+	for _, key := range keys {
+		row(key, stats[key].Hits, stats[key].Misses)
+	}
+
+	row("Total", hits, misses)
+
+	_ = writer.Flush()
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config [validate|export|migrate|trust]",
+	Short: "Interact with the config",
+	Long: `Interact with the config.
+
+You can do the following:
+
+- validate: report every problem in the config
+- export: convert the config to json, yaml or toml
+- migrate: migrate the config to the latest version
+- trust: approve the project config overlay`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/LNKLEO/OMP/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+// exportCmd represents the config export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the config",
+	Long: `Export the config in json, yaml or toml.
+
+Example usage:
+
+> omp config export --config ~/theme.omp.yaml --format toml --output ~/theme.omp.toml
+
+When --format is omitted, the format of the current config is used.
+When --output is omitted, the result is written to stdout.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg := config.Default(nil)
+
+		if configFile := config.Path(configFlag); len(configFile) != 0 {
+			var err error
+			cfg, err = config.Parse(configFile)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if len(exportOutput) == 0 {
+			content, err := cfg.Export(exportFormat)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Print(content)
+			return
+		}
+
+		cfg.Output = config.Path(exportOutput)
+		if err := cfg.Write(exportFormat); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "export format (json|yaml|toml)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "config file to write to")
+	configCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LNKLEO/OMP/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dryRun         bool
+	removeComments bool
+)
+
+// migrateCmd represents the config migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the config to the latest version",
+	Long: `Migrate the config to the latest version.
+
+Rewrites deprecated properties and template references and bumps the version.
+The result is written back to the config file in its original format,
+use --dry-run to print the changes instead.
+
+Writing the config formats it like config export does. Comments can't be kept,
+a config containing comments is only written when using --remove-comments.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		configFile := config.Path(configFlag)
+		if len(configFile) == 0 {
+			fmt.Println("no config file specified, use --config or OMP_THEME")
+			os.Exit(1)
+		}
+
+		cfg, err := config.Decode(configFile)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		version := cfg.DetectVersion()
+		if version >= config.Version {
+			fmt.Printf("%s is already at version %d\n", configFile, version)
+			return
+		}
+
+		// the diff compares both configs formatted the same way, so only the migrated values show up
+		original, err := config.Decode(configFile)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		cfg.Migrate()
+
+		hasComments := config.HasComments(configFile)
+
+		if !dryRun {
+			if hasComments && !removeComments {
+				fmt.Printf("%s contains comments which can't be kept, use --remove-comments to migrate it anyway\n", configFile)
+				os.Exit(1)
+			}
+
+			cfg.Output = configFile
+			if err := cfg.Write(""); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("migrated %s from version %d to %d\n", configFile, version, config.Version)
+			return
+		}
+
+		before, err := original.Export("")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		after, err := cfg.Export("")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if hasComments {
+			fmt.Println("# the comments in the config are removed when it's written")
+		}
+
+		fmt.Printf("--- %s (version %d)\n+++ %s (version %d)\n", configFile, version, configFile, config.Version)
+		fmt.Print(diff(before, after))
+	},
+}
+
+// diff returns a unified diff of the lines of a and b with 3 lines of context
+func diff(a, b string) string {
+	const context = 3
+
+	left := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	right := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] holds the length of the longest common subsequence of left[i:] and right[j:]
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+				continue
+			}
+
+			lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+		}
+	}
+
+	type line struct {
+		text   string
+		op     byte
+		li, ri int
+	}
+
+	var lines []line
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		switch {
+		case i < len(left) && j < len(right) && left[i] == right[j]:
+			lines = append(lines, line{op: ' ', text: left[i], li: i, ri: j})
+			i++
+			j++
+		case i < len(left) && (j == len(right) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{op: '-', text: left[i], li: i, ri: j})
+			i++
+		default:
+			lines = append(lines, line{op: '+', text: right[j], li: i, ri: j})
+			j++
+		}
+	}
+
+	var builder strings.Builder
+	for start := 0; start < len(lines); {
+		if lines[start].op == ' ' {
+			start++
+			continue
+		}
+
+		// extend the hunk until there are more than twice the context lines unchanged
+		end := start
+		for unchanged := 0; end < len(lines) && unchanged <= 2*context; end++ {
+			if lines[end].op == ' ' {
+				unchanged++
+				continue
+			}
+
+			unchanged = 0
+		}
+
+		from := max(start-context, 0)
+		to := end
+		for to > start && lines[to-1].op == ' ' {
+			to--
+		}
+		to = min(to+context, len(lines))
+
+		var leftCount, rightCount int
+		for _, l := range lines[from:to] {
+			if l.op != '+' {
+				leftCount++
+			}
+
+			if l.op != '-' {
+				rightCount++
+			}
+		}
+
+		fmt.Fprintf(&builder, "@@ -%d,%d +%d,%d @@\n", lines[from].li+1, leftCount, lines[from].ri+1, rightCount)
+		for _, l := range lines[from:to] {
+			fmt.Fprintf(&builder, "%c%s\n", l.op, l.text)
+		}
+
+		start = to
+	}
+
+	return builder.String()
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes instead of writing them")
+	migrateCmd.Flags().BoolVar(&removeComments, "remove-comments", false, "write the migrated config even though its comments are lost")
+	configCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/LNKLEO/OMP/config"
+	"github.com/LNKLEO/OMP/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// trustCmd represents the config trust command
+var trustCmd = &cobra.Command{
+	Use:   "trust [folder]",
+	Short: "Trust the project config overlay",
+	Long: `Trust the project config overlay.
+
+Looks for a .omp.yaml file in the given folder, or the current one, and its parents
+and approves its current content. Overlays that add or change command segments
+are ignored until they are trusted, any change to the file requires a new approval.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		flags := &runtime.Flags{}
+		if len(args) == 1 {
+			folder, err := filepath.Abs(args[0])
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			flags.PWD = folder
+		}
+
+		env := &runtime.Terminal{}
+		env.Init(flags)
+		defer env.Close()
+
+		overlay, err := env.HasParentFilePath(config.OverlayFileName, false)
+		if err != nil || overlay.IsDir {
+			fmt.Println("no", config.OverlayFileName, "found in", env.Pwd(), "or its parents")
+			os.Exit(1)
+		}
+
+		if err := config.Trust(overlay.Path); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Println("trusted", overlay.Path)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(trustCmd)
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/LNKLEO/OMP/config"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the config validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate the config",
+	Long: `Validate the config.
+
+Reports unknown segment types and properties, invalid styles and alignments,
+missing palette colors and templates that fail to parse.
+Exits with a non-zero code when a problem is found.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		configFile := configFlag
+		if len(args) == 1 {
+			configFile = args[0]
+		}
+
+		configFile = config.Path(configFile)
+		if len(configFile) == 0 {
+			fmt.Println("no config file specified, use --config or OMP_THEME")
+			os.Exit(1)
+		}
+
+		problems := config.Validate(configFile)
+		if len(problems) == 0 {
+			fmt.Println(configFile, "is valid")
+			return
+		}
+
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+
+		os.Exit(1)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(validateCmd)
+}
@@ -34,17 +34,23 @@ func createDebugCmd() *cobra.Command {
 			log.Enable()
 			log.Debug("debug mode enabled")
 
+			if len(traceFile) != 0 {
+				log.Record()
+			}
+
 			sh := os.Getenv("OMP_SHELL")
 
 			configFile := config.Path(configFlag)
 			cfg := config.Load(configFile, sh)
 
 			flags := &runtime.Flags{
-				Config: configFile,
-				Debug:  true,
-				PWD:    pwd,
-				Shell:  sh,
-				Plain:  plain,
+				Config:       configFile,
+				Debug:        true,
+				PWD:          pwd,
+				Shell:        sh,
+				Plain:        plain,
+				CacheBackend: cfg.CacheBackend,
+				RenderBudget: cfg.RenderBudget,
 			}
 
 			env := &runtime.Terminal{}
@@ -55,9 +61,10 @@ func createDebugCmd() *cobra.Command {
 			defer func() {
 				template.SaveCache()
 				env.Close()
+				writeTrace()
 			}()
 
-			terminal.Init(shell.GENERIC)
+			terminal.Init(shell.GENERIC, env.Getenv)
 			terminal.BackgroundColor = cfg.TerminalBackground.ResolveTemplate()
 			terminal.Colors = cfg.MakeColors(env)
 			terminal.Plain = plain
@@ -73,6 +80,7 @@ func createDebugCmd() *cobra.Command {
 	}
 
 	debugCmd.Flags().StringVar(&pwd, "pwd", "", "current working directory")
+	debugCmd.Flags().StringVar(&traceFile, "trace", "", "write a trace-event JSON file to inspect the timings")
 	debugCmd.Flags().BoolVarP(&plain, "plain", "p", false, "plain text output (no ANSI)")
 
 	// Deprecated flags, should be kept to avoid breaking CLI integration.
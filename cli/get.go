@@ -46,7 +46,8 @@ This command is used to get the value of the following variables:
 		}
 
 		flags := &runtime.Flags{
-			Shell: shellName,
+			Shell:        shellName,
+			CacheBackend: cacheBackend(),
 		}
 
 		env := &runtime.Terminal{}
@@ -20,6 +20,8 @@ var (
 	supportedShells = []string{
 		"bash",
 		"zsh",
+		"fish",
+		"nu",
 		"powershell",
 		"pwsh",
 		"cmd",
@@ -34,7 +36,7 @@ func init() {
 
 func createInitCmd() *cobra.Command {
 	initCmd := &cobra.Command{
-		Use:   "init [bash|zsh|powershell|pwsh|cmd]",
+		Use:   "init [bash|zsh|fish|nu|powershell|pwsh|cmd]",
 		Short: "Initialize your shell and config",
 		Long: "Initialize your shell and config",
 		ValidArgs: supportedShells,
@@ -71,12 +73,13 @@ func runInit(sh string) {
 	cfg := config.Load(configFile, sh)
 
 	flags := &runtime.Flags{
-		Shell:     sh,
-		Config:    configFile,
-		Strict:    strict,
-		Debug:     debug,
-		SaveCache: true,
-		Init:      true,
+		Shell:        sh,
+		Config:       configFile,
+		Strict:       strict,
+		Debug:        debug,
+		SaveCache:    true,
+		Init:         true,
+		CacheBackend: cfg.CacheBackend,
 	}
 
 	env := &runtime.Terminal{}
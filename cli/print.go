@@ -2,7 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/LNKLEO/OMP/log"
 	"github.com/LNKLEO/OMP/prompt"
 	"github.com/LNKLEO/OMP/runtime"
 	"github.com/LNKLEO/OMP/template"
@@ -22,12 +24,17 @@ var (
 	cleared       bool
 	jobCount      int
 	saveCache     bool
+	repaint       bool
+	daemon        bool
+	inlineRPrompt bool
 
 	command      string
 	shellVersion string
 	plain        bool
 	noStatus     bool
 	column       int
+	format       string
+	traceFile    string
 )
 
 // printCmd represents the prompt command
@@ -79,35 +86,36 @@ func createPrintCmd() *cobra.Command {
 				JobCount:      jobCount,
 				IsPrimary:     args[0] == prompt.PRIMARY,
 				SaveCache:     saveCache,
+				AsyncPID:      asyncPID,
+				Repaint:       repaint,
+				InlineRPrompt: inlineRPrompt,
 			}
 
-			eng := prompt.New(flags)
-
-			defer func() {
-				template.SaveCache()
-				eng.Env.Close()
-			}()
-
-			switch args[0] {
-			case prompt.DEBUG:
-				fmt.Print(eng.ExtraPrompt(prompt.Debug))
-			case prompt.PRIMARY:
-				fmt.Print(eng.Primary())
-			case prompt.SECONDARY:
-				fmt.Print(eng.ExtraPrompt(prompt.Secondary))
-			case prompt.TRANSIENT:
-				fmt.Print(eng.ExtraPrompt(prompt.Transient))
-			case prompt.RIGHT:
-				fmt.Print(eng.RPrompt())
-			case prompt.TOOLTIP:
-				fmt.Print(eng.Tooltip(command))
-			case prompt.VALID:
-				fmt.Print(eng.ExtraPrompt(prompt.Valid))
-			case prompt.ERROR:
-				fmt.Print(eng.ExtraPrompt(prompt.Error))
-			default:
-				_ = cmd.Help()
+			if daemon && len(traceFile) == 0 {
+				output, err := renderWithDaemon(flags, args[0], command, format)
+				if err == nil {
+					fmt.Print(output)
+					return
+				}
+
+				log.Debug("unable to render using the daemon:", err.Error())
+				startDaemon()
+			}
+
+			if len(traceFile) != 0 {
+				log.Record()
+			}
+
+			output, err := renderPrompt(flags, args[0], command, format)
+
+			writeTrace()
+
+			if err != nil {
+				fmt.Println(err)
+				return
 			}
+
+			fmt.Print(output)
 		},
 	}
 
@@ -128,9 +136,74 @@ func createPrintCmd() *cobra.Command {
 	printCmd.Flags().IntVar(&column, "column", 0, "the column position of the cursor")
 	printCmd.Flags().IntVar(&jobCount, "job-count", 0, "number of background jobs")
 	printCmd.Flags().BoolVar(&saveCache, "save-cache", false, "save updated cache to file")
+	printCmd.Flags().StringVar(&format, "format", "", "output format, use json for structured output")
+	printCmd.Flags().StringVar(&traceFile, "trace", "", "write a trace-event JSON file to inspect the timings")
+
+	printCmd.Flags().IntVar(&asyncPID, "async-pid", 0, "the shell process to signal when an async segment refreshed")
+	printCmd.Flags().BoolVar(&repaint, "repaint", false, "repaint after an async segment refreshed")
+	printCmd.Flags().BoolVar(&daemon, "daemon", false, "render using omp serve, start it when it's not running")
+	printCmd.Flags().BoolVar(&inlineRPrompt, "inline-rprompt", false, "render the right prompt as part of the primary prompt")
 
 	// Hide flags that are for internal use only.
 	_ = printCmd.Flags().MarkHidden("save-cache")
+	_ = printCmd.Flags().MarkHidden("async-pid")
+	_ = printCmd.Flags().MarkHidden("repaint")
+	_ = printCmd.Flags().MarkHidden("daemon")
+	_ = printCmd.Flags().MarkHidden("inline-rprompt")
 
 	return printCmd
 }
+
+// renderPrompt renders the prompt of the given type, as text or structured output
+func renderPrompt(flags *runtime.Flags, promptType, command, format string) (string, error) {
+	eng := prompt.New(flags)
+
+	defer func() {
+		template.SaveCache()
+		eng.Env.Close()
+	}()
+
+	var text string
+
+	switch promptType {
+	case prompt.DEBUG:
+		text = eng.ExtraPrompt(prompt.Debug)
+	case prompt.PRIMARY:
+		text = eng.Primary()
+	case prompt.SECONDARY:
+		text = eng.ExtraPrompt(prompt.Secondary)
+	case prompt.TRANSIENT:
+		text = eng.ExtraPrompt(prompt.Transient)
+	case prompt.RIGHT:
+		text = eng.RPrompt()
+	case prompt.TOOLTIP:
+		text = eng.Tooltip(command)
+	case prompt.VALID:
+		text = eng.ExtraPrompt(prompt.Valid)
+	case prompt.ERROR:
+		text = eng.ExtraPrompt(prompt.Error)
+	default:
+		return "", fmt.Errorf("unknown prompt type: %s", promptType)
+	}
+
+	if format != prompt.JSONFormat {
+		return text, nil
+	}
+
+	output, err := eng.JSON(promptType, text)
+	if err != nil {
+		return "", err
+	}
+
+	return output + "\n", nil
+}
+
+func writeTrace() {
+	if len(traceFile) == 0 {
+		return
+	}
+
+	if err := log.WriteTrace(traceFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
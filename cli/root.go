@@ -2,8 +2,12 @@ package cli
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/log"
+
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +26,9 @@ var RootCmd = &cobra.Command{
 	Use:   "OMP",
 	Short: "OMP is a tool to render my prompt",
 	Long: "OMP is a tool to render my prompt",
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		initLogFile()
+	},
 	Run: func(cmd *cobra.Command, _ []string) {
 		if initialize {
 			runInit(strings.ToLower(shellName))
@@ -39,6 +46,21 @@ func Execute() {
 	}
 }
 
+// initLogFile enables the log file when OMP_LOG_FILE is set,
+// a relative path is placed in the cache folder
+func initLogFile() {
+	logFile := os.Getenv("OMP_LOG_FILE")
+	if len(logFile) == 0 {
+		return
+	}
+
+	if !filepath.IsAbs(logFile) {
+		logFile = filepath.Join(cache.Path(), logFile)
+	}
+
+	log.SetFile(logFile, log.ParseLevel(os.Getenv("OMP_LOG_LEVEL")), cache.SessionID())
+}
+
 func init() {
 	RootCmd.PersistentFlags().StringVarP(&configFlag, "config", "c", "", "config file path")
 	RootCmd.PersistentFlags().BoolVar(&silent, "silent", false, "do not print anything")
@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/config"
+	"github.com/LNKLEO/OMP/log"
+	"github.com/LNKLEO/OMP/runtime"
+	"github.com/LNKLEO/OMP/runtime/cmd"
+	"github.com/LNKLEO/OMP/template"
+
+	json "github.com/goccy/go-json"
+	"github.com/spf13/cobra"
+)
+
+const (
+	socketDirName  = "serve"
+	socketFileName = "omp.sock"
+
+	// dialTimeout keeps the prompt fast when the daemon isn't running
+	dialTimeout    = 50 * time.Millisecond
+	requestTimeout = 10 * time.Second
+)
+
+var idleTimeout time.Duration
+
+// renderRequest holds everything the daemon needs to render a prompt like the shell would,
+// the flags include the working folder, environment and session of the shell
+type renderRequest struct {
+	Flags      *runtime.Flags `json:"flags"`
+	Type       string         `json:"type"`
+	Command    string         `json:"command,omitempty"`
+	Format     string         `json:"format,omitempty"`
+	Executable string         `json:"executable"`
+}
+
+type renderResponse struct {
+	Prompt string `json:"prompt"`
+	Error  string `json:"error,omitempty"`
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Render prompts from a background process",
+	Long: `Render prompts from a background process.
+
+The process listens on a socket in a folder of the cache folder that only
+the current user can access, and keeps the config, templates and caches
+in memory between prompts. Processes of other users are refused.
+
+Set "daemon" to true in the config to render the prompts using this process,
+it is started automatically when it isn't running.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := serve(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().DurationVar(&idleTimeout, "idle-timeout", time.Hour, "stop after not rendering a prompt for this long, 0 to keep running")
+	RootCmd.AddCommand(serveCmd)
+}
+
+func socketPath() string {
+	return filepath.Join(cache.Path(), socketDirName, socketFileName)
+}
+
+// executableID identifies the binary, a daemon started by a previous version must not render prompts
+func executableID() string {
+	executable, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+
+	info, err := os.Stat(executable)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s.%d.%d", executable, info.Size(), info.ModTime().UnixNano())
+}
+
+type server struct {
+	listener net.Listener
+	activity chan bool
+	id       string
+	// renders share the warm caches and the template state, so only one can run at a time
+	mutex sync.Mutex
+}
+
+func serve() error {
+	socket := socketPath()
+
+	if conn, err := net.DialTimeout("unix", socket, dialTimeout); err == nil {
+		conn.Close()
+		return errors.New("omp serve is already running")
+	}
+
+	dir := filepath.Dir(socket)
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	if err := checkPrivateDir(dir); err != nil {
+		return err
+	}
+
+	// remove the socket of a process that didn't stop cleanly
+	_ = os.Remove(socket)
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(socket, 0o600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	runtime.KeepWarm()
+	config.KeepWarm()
+	template.KeepWarm()
+
+	srv := &server{
+		listener: listener,
+		activity: make(chan bool),
+		id:       executableID(),
+	}
+
+	go srv.stopWhenDone()
+
+	log.Debug("listening on", socket)
+
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		go srv.handle(conn)
+	}
+}
+
+// stopWhenDone closes the listener when we're asked to stop or didn't render anything for a while
+func (srv *server) stopWhenDone() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	var idle <-chan time.Time
+
+	for {
+		if idleTimeout > 0 {
+			idle = time.After(idleTimeout)
+		}
+
+		select {
+		case <-srv.activity:
+			continue
+		case <-idle:
+			log.Debug("stopping after being idle for", idleTimeout.String())
+		case <-signals:
+		}
+
+		srv.listener.Close()
+		return
+	}
+}
+
+func (srv *server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return
+	}
+
+	allowed, err := peerAllowed(unixConn)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if !allowed {
+		log.Debug("refusing a connection from another user")
+		return
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	var request renderRequest
+	if err := json.NewDecoder(conn).Decode(&request); err != nil {
+		log.Error(err)
+		return
+	}
+
+	select {
+	case srv.activity <- true:
+	default:
+	}
+
+	response := srv.render(&request)
+
+	if err := json.NewEncoder(conn).Encode(response); err != nil {
+		log.Error(err)
+	}
+}
+
+func (srv *server) render(request *renderRequest) (response *renderResponse) {
+	if request.Executable != srv.id {
+		// let the next prompt start a daemon using the current executable
+		srv.listener.Close()
+		return &renderResponse{Error: "omp serve runs another executable"}
+	}
+
+	if request.Flags == nil {
+		return &renderResponse{Error: "no flags provided"}
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Errorf("unable to render the prompt: %v", err))
+			response = &renderResponse{Error: fmt.Sprint(err)}
+		}
+	}()
+
+	// the template cache belongs to the shell session that requests the prompt
+	template.Cache = nil
+
+	output, err := renderPrompt(request.Flags, request.Type, request.Command, request.Format)
+	if err != nil {
+		return &renderResponse{Error: err.Error()}
+	}
+
+	return &renderResponse{Prompt: output}
+}
+
+// renderWithDaemon asks omp serve to render the prompt
+func renderWithDaemon(flags *runtime.Flags, promptType, command, format string) (string, error) {
+	conn, err := net.DialTimeout("unix", socketPath(), dialTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	// the daemon has another working folder, environment and parent process, make sure it uses ours
+	requestFlags := *flags
+	requestFlags.Cwd = cwd
+	requestFlags.Environ = os.Environ()
+	requestFlags.SessionID = cache.SessionID()
+
+	request := &renderRequest{
+		Flags:      &requestFlags,
+		Type:       promptType,
+		Command:    command,
+		Format:     format,
+		Executable: executableID(),
+	}
+
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return "", err
+	}
+
+	// the request is complete, the daemon doesn't need to wait for more data
+	if unixConn, ok := conn.(*net.UnixConn); ok {
+		_ = unixConn.CloseWrite()
+	}
+
+	var response renderResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return "", err
+	}
+
+	if len(response.Error) != 0 {
+		return "", errors.New(response.Error)
+	}
+
+	return response.Prompt, nil
+}
+
+func startDaemon() {
+	executable, err := os.Executable()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if err := cmd.Detach(executable, []string{"serve"}, nil); err != nil {
+		log.Error(err)
+	}
+}
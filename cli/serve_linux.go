@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerAllowed only accepts processes of the user running omp serve
+func peerAllowed(conn *net.UnixConn) (bool, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	var cred *unix.Ucred
+	var credErr error
+
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	if credErr != nil {
+		return false, credErr
+	}
+
+	return int(cred.Uid) == os.Getuid(), nil
+}
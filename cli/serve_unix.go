@@ -0,0 +1,31 @@
+//go:build !windows
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateDir makes sure only the user running omp serve can access the folder containing the socket
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a folder", dir)
+	}
+
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by another user", dir)
+	}
+
+	if info.Mode().Perm() == 0o700 {
+		return nil
+	}
+
+	return os.Chmod(dir, 0o700)
+}
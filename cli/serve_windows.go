@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkPrivateDir makes sure the socket is in a folder, the cache folder
+// is in the profile of the user so other users can't access it
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a folder", dir)
+	}
+
+	return nil
+}
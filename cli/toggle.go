@@ -21,7 +21,8 @@ var toggleCmd = &cobra.Command{
 		}
 
 		flags := &runtime.Flags{
-			SaveCache: true,
+			SaveCache:    true,
+			CacheBackend: cacheBackend(),
 		}
 
 		env := &runtime.Terminal{}
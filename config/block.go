@@ -9,6 +9,9 @@ type BlockAlignment string
 // Overflow defines how to handle a right block that overflows with the previous block
 type Overflow string
 
+// TruncateSide defines which side of the text gets removed when truncating a segment
+type TruncateSide string
+
 const (
 	// Prompt writes one or more Segments
 	Prompt BlockType = "prompt"
@@ -22,14 +25,22 @@ const (
 	Break Overflow = "break"
 	// Hide hides the block
 	Hide Overflow = "hide"
+	// Truncate shortens the text of the longest segments, hiding the block when that's not enough
+	Truncate Overflow = "truncate"
+	// TruncateStart removes the start of the text
+	TruncateStart TruncateSide = "start"
+	// TruncateEnd removes the end of the text
+	TruncateEnd TruncateSide = "end"
 )
 
 // Block defines a part of the prompt with optional segments
 type Block struct {
 	Type            BlockType      `json:"type,omitempty" toml:"type,omitempty"`
 	Alignment       BlockAlignment `json:"alignment,omitempty" toml:"alignment,omitempty"`
+	Alias           string         `json:"alias,omitempty" toml:"alias,omitempty"`
 	Filler          string         `json:"filler,omitempty" toml:"filler,omitempty"`
 	Overflow        Overflow       `json:"overflow,omitempty" toml:"overflow,omitempty"`
+	TruncateSide    TruncateSide   `json:"truncate_side,omitempty" toml:"truncate_side,omitempty"`
 	LeadingDiamond  string         `json:"leading_diamond,omitempty" toml:"leading_diamond,omitempty"`
 	TrailingDiamond string         `json:"trailing_diamond,omitempty" toml:"trailing_diamond,omitempty"`
 	Segments        []*Segment     `json:"segments,omitempty" toml:"segments,omitempty"`
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"slices"
+
+	"github.com/LNKLEO/OMP/cache"
 	"github.com/LNKLEO/OMP/color"
 	"github.com/LNKLEO/OMP/log"
 	"github.com/LNKLEO/OMP/runtime"
@@ -29,20 +32,25 @@ type Config struct {
 	ErrorLine               *Segment        `json:"error_line,omitempty" toml:"error_line,omitempty"`
 	TerminalBackground      color.Ansi      `json:"terminal_background,omitempty" toml:"terminal_background,omitempty"`
 	origin                  string
+	extended                []string
 	PWD                     string                 `json:"pwd,omitempty" toml:"pwd,omitempty"`
 	AccentColor             color.Ansi             `json:"accent_color,omitempty" toml:"accent_color,omitempty"`
 	Output                  string                 `json:"-" toml:"-"`
 	ConsoleTitleTemplate    string                 `json:"console_title_template,omitempty" toml:"console_title_template,omitempty"`
 	Format                  string                 `json:"-" toml:"-"`
+	CacheBackend            cache.Backend          `json:"cache_backend,omitempty" toml:"cache_backend,omitempty"`
+	Extends                 string                 `json:"extends,omitempty" toml:"extends,omitempty"`
 	Cycle                   color.Cycle            `json:"cycle,omitempty" toml:"cycle,omitempty"`
 	Blocks                  []*Block               `json:"blocks,omitempty" toml:"blocks,omitempty"`
 	Tooltips                []*Segment             `json:"tooltips,omitempty" toml:"tooltips,omitempty"`
 	Version                 int                    `json:"version" toml:"version"`
+	RenderBudget            int                    `json:"render_budget,omitempty" toml:"render_budget,omitempty"`
 	ShellIntegration        bool                   `json:"shell_integration,omitempty" toml:"shell_integration,omitempty"`
 	MigrateGlyphs           bool                   `json:"-" toml:"-"`
 	PatchPwshBleed          bool                   `json:"patch_pwsh_bleed,omitempty" toml:"patch_pwsh_bleed,omitempty"`
 	EnableCursorPositioning bool                   `json:"enable_cursor_positioning,omitempty" toml:"enable_cursor_positioning,omitempty"`
 	FinalSpace              bool                   `json:"final_space,omitempty" toml:"final_space,omitempty"`
+	Daemon                  bool                   `json:"daemon,omitempty" toml:"daemon,omitempty"`
 }
 
 func (cfg *Config) MakeColors(env runtime.Environment) color.String {
@@ -98,6 +106,11 @@ func (cfg *Config) Features(env runtime.Environment) shell.Features {
 		feats = append(feats, shell.LineError)
 	}
 
+	if cfg.Daemon {
+		log.Debug("daemon enabled")
+		feats = append(feats, shell.Daemon)
+	}
+
 	if len(cfg.Tooltips) > 0 {
 		log.Debug("tooltips enabled")
 		feats = append(feats, shell.Tooltips)
@@ -123,6 +136,11 @@ func (cfg *Config) Features(env runtime.Environment) shell.Features {
 				}
 			}
 
+			if segment.Async && !slices.Contains(feats, shell.Async) {
+				log.Debug("async segments enabled")
+				feats = append(feats, shell.Async)
+			}
+
 			if segment.Type == GIT {
 				source := segment.Properties.GetString(segments.Source, segments.Cli)
 				if source == segments.Pwsh {
@@ -1,6 +1,70 @@
 package config
 
-func Default(warning bool) *Config {
-	cfg := &Config{}
-	return cfg
+import (
+	"bytes"
+	_ "embed"
+	"strings"
+
+	"github.com/LNKLEO/OMP/log"
+
+	json "github.com/goccy/go-json"
+)
+
+//go:embed default.omp.json
+var defaultConfig []byte
+
+// configErrorLength keeps the error segment from taking up the whole line
+const configErrorLength = 50
+
+// Default returns the built-in theme. When err is set, the user's
+// configuration could not be loaded and we prepend an error segment
+// so that the failure is visible in the prompt.
+func Default(err error) *Config {
+	var cfg Config
+
+	decoder := json.NewDecoder(bytes.NewReader(defaultConfig))
+	if err := decoder.Decode(&cfg); err != nil {
+		log.Error(err)
+		return &Config{}
+	}
+
+	cfg.Format = JSON
+
+	if err == nil || len(cfg.Blocks) == 0 {
+		return &cfg
+	}
+
+	// the message is passed as a variable, it's not a template itself
+	cfg.Var = map[string]any{
+		"ConfigError": configErrorText(err),
+	}
+
+	configError := &Segment{
+		Type:            TEXT,
+		Alias:           "ConfigError",
+		Style:           Powerline,
+		PowerlineSymbol: "",
+		Foreground:      "p:white",
+		Background:      "p:red",
+		Template:        "  CONFIG ERROR: {{ .Var.ConfigError }} ",
+	}
+
+	// the error segment starts the line now, a leading diamond would show up in the middle of it
+	cfg.Blocks[0].Segments[0].LeadingDiamond = ""
+
+	cfg.Blocks[0].Segments = append([]*Segment{configError}, cfg.Blocks[0].Segments...)
+
+	return &cfg
+}
+
+// configErrorText returns the first line of the error, parse errors can include an excerpt of the file
+func configErrorText(err error) string {
+	text, _, _ := strings.Cut(err.Error(), "\n")
+	text = strings.TrimSpace(text)
+
+	if runes := []rune(text); len(runes) > configErrorLength {
+		text = string(runes[:configErrorLength-1]) + "…"
+	}
+
+	return text
 }
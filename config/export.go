@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	stdOS "os"
+	"slices"
+	"strings"
+
+	"github.com/LNKLEO/OMP/properties"
+
+	json "github.com/goccy/go-json"
+	yaml "github.com/goccy/go-yaml"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Export serializes the config in the given format, defaulting to the format it was loaded from.
+// Struct fields keep their declaration order and map keys are sorted, so the output is stable.
+func (cfg *Config) Export(format string) (string, error) {
+	if len(format) != 0 {
+		cfg.Format = format
+	}
+
+	cfg.Version = Version
+	cfg.restoreIntegers()
+
+	var result bytes.Buffer
+
+	switch cfg.Format {
+	case JSON:
+		encoder := json.NewEncoder(&result)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(cfg); err != nil {
+			return "", err
+		}
+	case YAML, "yml":
+		cfg.Format = YAML
+		encoder := yaml.NewEncoder(&result, yaml.Indent(2), yaml.IndentSequence(true))
+		if err := encoder.Encode(cfg); err != nil {
+			return "", err
+		}
+	case TOML, "tml":
+		cfg.Format = TOML
+		encoder := toml.NewEncoder(&result)
+		encoder.SetIndentTables(true)
+		if err := encoder.Encode(cfg); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported config file format: %s", cfg.Format)
+	}
+
+	return strings.TrimSpace(result.String()) + "\n", nil
+}
+
+// Write exports the config in the given format to the Output file
+func (cfg *Config) Write(format string) error {
+	content, err := cfg.Export(format)
+	if err != nil {
+		return err
+	}
+
+	return stdOS.WriteFile(cfg.Output, []byte(content), 0o644)
+}
+
+// restoreIntegers turns the whole numbers in the properties and variables, which JSON decodes
+// as float64, back into integers so exporting doesn't change max_depth = 2 into 2.0
+func (cfg *Config) restoreIntegers() {
+	for key, value := range cfg.Var {
+		cfg.Var[key] = restoreInteger(value)
+	}
+
+	segments := slices.Clone(cfg.Tooltips)
+	for _, block := range cfg.Blocks {
+		segments = append(segments, block.Segments...)
+	}
+
+	segments = append(segments, cfg.DebugPrompt, cfg.ValidLine, cfg.ErrorLine, cfg.SecondaryPrompt, cfg.TransientPrompt)
+
+	for _, segment := range segments {
+		if segment == nil {
+			continue
+		}
+
+		for key, value := range segment.Properties {
+			segment.Properties[key] = restoreInteger(value)
+		}
+	}
+}
+
+func restoreInteger(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+			return int(v)
+		}
+	case map[string]any:
+		for key, item := range v {
+			v[key] = restoreInteger(item)
+		}
+	case properties.Map:
+		for key, item := range v {
+			v[key] = restoreInteger(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = restoreInteger(item)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/LNKLEO/OMP/log"
+	"github.com/LNKLEO/OMP/runtime/path"
+
+	json "github.com/goccy/go-json"
+)
+
+// extend resolves the extends chain of the config file and decodes
+// the result of merging every child config over its parent.
+//
+// Merging happens on the raw documents rather than on the decoded Config,
+// so a child only overrides the keys it actually sets.
+func extend(configFile string) (*Config, error) {
+	var m merger
+	raw, err := m.resolve(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	// the first entry is the config file itself
+	cfg.extended = m.chain[1:]
+
+	return &cfg, nil
+}
+
+// resolveSources merges the extends chain of the config file like extend does,
+// and returns the raw result together with where each of its values is defined
+func resolveSources(configFile string) (map[string]any, *sources, error) {
+	m := merger{
+		sources: &sources{
+			objects: make(map[uintptr]source),
+			keys:    make(map[sourceKey]source),
+		},
+	}
+
+	raw, err := m.resolve(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return raw, m.sources, nil
+}
+
+// merger merges the raw documents of an extends chain, keeping track
+// of every visited file in chain to detect cycles
+type merger struct {
+	sources *sources
+	chain   []string
+}
+
+func (m *merger) resolve(configFile string) (map[string]any, error) {
+	configFile, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(m.chain, configFile) {
+		return nil, fmt.Errorf("cyclic config extends: %s -> %s", strings.Join(m.chain, " -> "), configFile)
+	}
+
+	m.chain = append(m.chain, configFile)
+
+	raw := make(map[string]any)
+	if _, err := unmarshal(configFile, &raw); err != nil {
+		return nil, err
+	}
+
+	parent, _ := raw["extends"].(string)
+	delete(raw, "extends")
+
+	if m.sources != nil {
+		m.sources.add(configFile, "", raw)
+	}
+
+	if len(parent) == 0 {
+		return raw, nil
+	}
+
+	parent = path.ReplaceTildePrefixWithHomeDir(parent)
+	if !filepath.IsAbs(parent) {
+		parent = filepath.Join(filepath.Dir(configFile), parent)
+	}
+
+	log.Debugf("config %s extends %s", configFile, parent)
+
+	base, err := m.resolve(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.mergeConfig(base, raw), nil
+}
+
+// set stores the value for key in target, which the child document defined
+func (m *merger) set(target, child map[string]any, key string, value any) {
+	target[key] = value
+
+	if m.sources != nil {
+		m.sources.moved(target, child, key)
+	}
+}
+
+// mergeConfig merges the raw child config over the raw base config
+func (m *merger) mergeConfig(base, child map[string]any) map[string]any {
+	for key, value := range child {
+		switch key {
+		case "blocks":
+			m.set(base, child, key, m.mergeBlocks(base[key], value))
+		case "tooltips":
+			m.set(base, child, key, m.mergeSegments(base[key], value))
+		default:
+			m.set(base, child, key, m.mergeValue(base[key], value))
+		}
+	}
+
+	return base
+}
+
+// mergeValue merges maps key by key, any other child value replaces the parent value
+func (m *merger) mergeValue(parent, child any) any {
+	parentMap, ok := parent.(map[string]any)
+	if !ok {
+		return child
+	}
+
+	childMap, ok := child.(map[string]any)
+	if !ok {
+		return child
+	}
+
+	for key, value := range childMap {
+		m.set(parentMap, childMap, key, m.mergeValue(parentMap[key], value))
+	}
+
+	return parentMap
+}
+
+// mergeBlocks matches blocks by alias, or by index when the child block has no alias
+func (m *merger) mergeBlocks(parent, child any) any {
+	parentBlocks, ok := parent.([]any)
+	if !ok {
+		return child
+	}
+
+	childBlocks, ok := child.([]any)
+	if !ok {
+		return child
+	}
+
+	for i, value := range childBlocks {
+		block, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		index := findByAlias(parentBlocks, block)
+		if index == -1 && len(alias(block)) == 0 && i < len(parentBlocks) {
+			index = i
+		}
+
+		if index == -1 {
+			parentBlocks = append(parentBlocks, block)
+			continue
+		}
+
+		parentBlock, _ := parentBlocks[index].(map[string]any)
+		if parentBlock == nil {
+			parentBlocks[index] = block
+			continue
+		}
+
+		for key, value := range block {
+			if key == "segments" {
+				m.set(parentBlock, block, key, m.mergeSegments(parentBlock[key], value))
+				continue
+			}
+
+			m.set(parentBlock, block, key, m.mergeValue(parentBlock[key], value))
+		}
+	}
+
+	return parentBlocks
+}
+
+// mergeSegments merges segments with a matching alias, other child segments are appended
+func (m *merger) mergeSegments(parent, child any) any {
+	parentSegments, ok := parent.([]any)
+	if !ok {
+		return child
+	}
+
+	childSegments, ok := child.([]any)
+	if !ok {
+		return child
+	}
+
+	for _, value := range childSegments {
+		segment, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		index := findByAlias(parentSegments, segment)
+		if index == -1 {
+			parentSegments = append(parentSegments, segment)
+			continue
+		}
+
+		parentSegments[index] = m.mergeValue(parentSegments[index], segment)
+	}
+
+	return parentSegments
+}
+
+func alias(item map[string]any) string {
+	value, _ := item["alias"].(string)
+	return value
+}
+
+func findByAlias(items []any, item map[string]any) int {
+	name := alias(item)
+	if len(name) == 0 {
+		return -1
+	}
+
+	for i, value := range items {
+		if candidate, ok := value.(map[string]any); ok && alias(candidate) == name {
+			return i
+		}
+	}
+
+	return -1
+}
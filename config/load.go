@@ -11,7 +11,6 @@ import (
 
 	"github.com/LNKLEO/OMP/log"
 	"github.com/LNKLEO/OMP/runtime/path"
-	"github.com/gookit/goutil/jsonutil"
 
 	json "github.com/goccy/go-json"
 	yaml "github.com/goccy/go-yaml"
@@ -27,6 +26,11 @@ func Load(configFile, sh string) *Config {
 }
 
 func Path(config string) string {
+	return PathFrom(config, os.Getenv)
+}
+
+// PathFrom returns the config file to use, falling back to OMP_THEME in the environment getenv reads from
+func PathFrom(config string, getenv func(string) string) string {
 	defer log.Trace(time.Now())
 
 	// if the config flag is set, we'll use that over OMP_THEME
@@ -34,7 +38,7 @@ func Path(config string) string {
 	// due to not using --config to set the configuration
 	hasConfig := len(config) > 0
 
-	if OMPTheme := os.Getenv("OMP_THEME"); len(OMPTheme) > 0 && !hasConfig {
+	if OMPTheme := getenv("OMP_THEME"); len(OMPTheme) > 0 && !hasConfig {
 		log.Debug("config set using OMP_THEME:", OMPTheme)
 		return OMPTheme
 	}
@@ -59,42 +63,104 @@ func loadConfig(configFile string) *Config {
 
 	if len(configFile) == 0 {
 		log.Debug("no config file specified, using default")
-		return Default(false)
+		return Default(nil)
+	}
+
+	if cfg, ok := loadSnapshot(configFile); ok {
+		return cfg
+	}
+
+	cfg, err := Parse(configFile)
+	if err != nil {
+		log.Error(err)
+		return Default(err)
+	}
+
+	saveSnapshot(cfg)
+
+	return cfg
+}
+
+// Parse reads and decodes the config file, including the configs it extends,
+// without falling back to the default config
+func Parse(configFile string) (*Config, error) {
+	cfg, err := Decode(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Extends) == 0 {
+		return cfg, nil
 	}
 
+	merged, err := extend(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	merged.origin = cfg.origin
+	merged.Format = cfg.Format
+
+	return merged, nil
+}
+
+// Decode reads and decodes the config file on its own, ignoring the configs it extends
+func Decode(configFile string) (*Config, error) {
 	var cfg Config
+
+	format, err := unmarshal(configFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg.origin = configFile
-	cfg.Format = strings.TrimPrefix(filepath.Ext(configFile), ".")
+	cfg.Format = format
+
+	return &cfg, nil
+}
+
+// unmarshal decodes the config file into v, based on the file extension,
+// and returns the format it was written in
+func unmarshal(configFile string, v any) (string, error) {
+	format := configFormat(configFile)
 
 	data, err := stdOS.ReadFile(configFile)
 	if err != nil {
-		log.Error(err)
-		return Default(true)
+		return format, err
 	}
 
-	switch cfg.Format {
-	case "yml", "yaml":
-		cfg.Format = YAML
-		err = yaml.Unmarshal(data, &cfg)
-	case "jsonc", "json":
-		cfg.Format = JSON
-
-		str := jsonutil.StripComments(string(data))
-		data = []byte(str)
+	switch format {
+	case YAML:
+		err = yaml.Unmarshal(data, v)
+	case JSON:
+		data = blankComments(data)
 
 		decoder := json.NewDecoder(bytes.NewReader(data))
-		err = decoder.Decode(&cfg)
-	case "toml", "tml":
-		cfg.Format = TOML
-		err = toml.Unmarshal(data, &cfg)
+		err = decoder.Decode(v)
+	case TOML:
+		err = toml.Unmarshal(data, v)
 	default:
-		err = fmt.Errorf("unsupported config file format: %s", cfg.Format)
+		return format, fmt.Errorf("unsupported config file format: %s", strings.TrimPrefix(filepath.Ext(configFile), "."))
 	}
 
 	if err != nil {
-		log.Error(err)
-		return Default(true)
+		return format, positionedError(configFile, data, err)
 	}
 
-	return &cfg
+	return format, nil
+}
+
+// configFormat returns the format of the config file based on its extension,
+// or an empty string when it's not supported
+func configFormat(configFile string) string {
+	switch strings.TrimPrefix(filepath.Ext(configFile), ".") {
+	case "yml", "yaml":
+		return YAML
+	case "jsonc", "json":
+		return JSON
+	case "toml", "tml":
+		return TOML
+	default:
+		return ""
+	}
 }
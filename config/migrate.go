@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	stdOS "os"
+
+	"github.com/LNKLEO/OMP/properties"
+	"github.com/LNKLEO/OMP/regex"
+	"github.com/LNKLEO/OMP/segments"
+
+	"github.com/goccy/go-yaml/lexer"
+	"github.com/goccy/go-yaml/token"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+const (
+	// deprecated segment properties from version 1, replaced by the segment template
+	templateProperty properties.Property = "template"
+	prefixProperty   properties.Property = "prefix"
+	postfixProperty  properties.Property = "postfix"
+	textProperty     properties.Property = "text"
+)
+
+// renamedProperties contains the properties that got renamed in version 2, per segment type
+var renamedProperties = map[SegmentType]map[properties.Property]properties.Property{
+	GIT: {
+		"display_status":         segments.FetchStatus,
+		"display_stash_count":    segments.FetchStashCount,
+		"display_worktree_count": segments.FetchWorktreeCount,
+		"display_upstream_icon":  segments.FetchUpstreamIcon,
+	},
+	NODE: {
+		"display_package_manager": segments.FetchPackageManager,
+	},
+	PYTHON: {
+		"display_virtual_env": segments.FetchVirtualEnv,
+	},
+}
+
+// DetectVersion returns the schema version of the config, configs without a version are version 1
+func (cfg *Config) DetectVersion() int {
+	if cfg.Version == 0 {
+		return 1
+	}
+
+	return cfg.Version
+}
+
+// HasComments returns true when the config file contains comments,
+// those are lost when the migrated config gets written
+func HasComments(configFile string) bool {
+	data, err := stdOS.ReadFile(configFile)
+	if err != nil {
+		return false
+	}
+
+	switch configFormat(configFile) {
+	case JSON:
+		return !bytes.Equal(blankComments(data), data)
+	case YAML:
+		for _, tok := range lexer.Tokenize(string(data)) {
+			if tok.Type == token.CommentType {
+				return true
+			}
+		}
+	case TOML:
+		tomlParser := unstable.Parser{KeepComments: true}
+		tomlParser.Reset(data)
+
+		for tomlParser.NextExpression() {
+			// a comment at the end of a line is chained to the expression
+			for node := tomlParser.Expression(); node != nil; node = node.Next() {
+				if node.Kind == unstable.Comment {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// Migrate rewrites deprecated properties and template references
+// to the current schema and stamps the current Version
+func (cfg *Config) Migrate() {
+	version := cfg.DetectVersion()
+
+	for _, block := range cfg.Blocks {
+		for _, segment := range block.Segments {
+			segment.migrate(version)
+		}
+	}
+
+	for _, tooltip := range cfg.Tooltips {
+		tooltip.migrate(version)
+	}
+
+	cfg.Version = Version
+}
+
+func (segment *Segment) migrate(version int) {
+	if version < 2 {
+		segment.migrationTwo()
+	}
+
+	if version < 3 {
+		segment.migrationThree()
+	}
+}
+
+// migrationTwo moves the template related properties to the segment template
+// and renames the display_* properties that got replaced by fetch_*
+func (segment *Segment) migrationTwo() {
+	if segment.Properties == nil {
+		return
+	}
+
+	for old, current := range renamedProperties[segment.Type] {
+		value, ok := segment.Properties[old]
+		if !ok {
+			continue
+		}
+
+		delete(segment.Properties, old)
+		segment.Properties[current] = value
+	}
+
+	if segment.Type == TEXT {
+		segment.migrateTemplateProperty(textProperty)
+	}
+
+	segment.migrateTemplateProperty(templateProperty)
+
+	prefix := segment.Properties.GetString(prefixProperty, "")
+	postfix := segment.Properties.GetString(postfixProperty, "")
+	delete(segment.Properties, prefixProperty)
+	delete(segment.Properties, postfixProperty)
+
+	if len(prefix) == 0 && len(postfix) == 0 {
+		return
+	}
+
+	if len(segment.Template) == 0 {
+		if f, ok := Segments[segment.Type]; ok {
+			segment.Template = f().Template()
+		}
+	}
+
+	segment.Template = prefix + segment.Template + postfix
+}
+
+func (segment *Segment) migrateTemplateProperty(property properties.Property) {
+	value := segment.Properties.GetString(property, "")
+	delete(segment.Properties, property)
+
+	if len(value) == 0 || len(segment.Template) != 0 {
+		return
+	}
+
+	segment.Template = value
+}
+
+// migrationThree replaces the deprecated .Meaning on the status segment
+func (segment *Segment) migrationThree() {
+	if segment.Type != STATUS && segment.Type != EXIT {
+		return
+	}
+
+	migrate := func(template string) string {
+		template = regex.ReplaceAllString(`{{-?\s*\.Meaning\s*-?}}`, template, "{{ reason .Code }}")
+		return regex.ReplaceAllString(`\.Meaning\b`, template, "(reason .Code)")
+	}
+
+	segment.Template = migrate(segment.Template)
+
+	for i, template := range segment.Templates {
+		segment.Templates[i] = migrate(template)
+	}
+
+	if statusTemplate, ok := segment.Properties[segments.StatusTemplate].(string); ok {
+		segment.Properties[segments.StatusTemplate] = migrate(statusTemplate)
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	stdOS "os"
+	"path/filepath"
+	"time"
+
+	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/log"
+
+	json "github.com/goccy/go-json"
+	yaml "github.com/goccy/go-yaml"
+)
+
+const (
+	// OverlayFileName is the project local config that gets merged over the user's config
+	OverlayFileName = ".omp.yaml"
+
+	trustFileName = "trusted_overlays.json"
+)
+
+// Overlay merges the project local overlay over the config.
+//
+// An overlay that adds or changes command segments is only applied
+// once it has been approved using Trust, to avoid running commands
+// from repositories that haven't been reviewed.
+func (cfg *Config) Overlay(overlayFile string) {
+	defer log.Trace(time.Now(), overlayFile)
+
+	data, err := stdOS.ReadFile(overlayFile)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	overlay := make(map[string]any)
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		log.Error(err)
+		return
+	}
+
+	delete(overlay, "extends")
+
+	merged, err := cfg.merge(overlay)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if !commandSegmentsEqual(cfg, merged) && !isTrusted(overlayFile, data) {
+		log.Debugf("ignoring untrusted overlay %s as it changes command segments, use omp config trust to approve it", overlayFile)
+		return
+	}
+
+	merged.origin = cfg.origin
+	merged.Format = cfg.Format
+	merged.Output = cfg.Output
+	merged.MigrateGlyphs = cfg.MigrateGlyphs
+
+	*cfg = *merged
+}
+
+func (cfg *Config) merge(overlay map[string]any) (*Config, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	base := make(map[string]any)
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+
+	var m merger
+	data, err = json.Marshal(m.mergeConfig(base, overlay))
+	if err != nil {
+		return nil, err
+	}
+
+	var merged Config
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+
+	return &merged, nil
+}
+
+func commandSegments(cfg *Config) map[string]int {
+	result := make(map[string]int)
+
+	add := func(segment *Segment) {
+		if segment == nil || segment.Type != CMD {
+			return
+		}
+
+		data, _ := json.Marshal(segment)
+		result[string(data)]++
+	}
+
+	for _, block := range cfg.Blocks {
+		for _, segment := range block.Segments {
+			add(segment)
+		}
+	}
+
+	for _, tooltip := range cfg.Tooltips {
+		add(tooltip)
+	}
+
+	return result
+}
+
+func commandSegmentsEqual(cfg, other *Config) bool {
+	before := commandSegments(cfg)
+	after := commandSegments(other)
+
+	if len(before) != len(after) {
+		return false
+	}
+
+	for key, count := range after {
+		if before[key] != count {
+			return false
+		}
+	}
+
+	return true
+}
+
+func trustFile() string {
+	return filepath.Join(cache.Path(), trustFileName)
+}
+
+// trustedOverlays maps every approved overlay file to the hash of its content
+func trustedOverlays() map[string]string {
+	trusted := make(map[string]string)
+
+	data, err := stdOS.ReadFile(trustFile())
+	if err != nil {
+		return trusted
+	}
+
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		log.Error(err)
+	}
+
+	return trusted
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func isTrusted(overlayFile string, data []byte) bool {
+	trusted, ok := trustedOverlays()[overlayFile]
+	return ok && trusted == hash(data)
+}
+
+// Trust approves the current content of the overlay file,
+// any later change to the file requires a new approval
+func Trust(overlayFile string) error {
+	if len(overlayFile) == 0 {
+		return errors.New("no overlay file to trust")
+	}
+
+	data, err := stdOS.ReadFile(overlayFile)
+	if err != nil {
+		return err
+	}
+
+	trusted := trustedOverlays()
+	trusted[overlayFile] = hash(data)
+
+	content, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return stdOS.WriteFile(trustFile(), content, 0o644)
+}
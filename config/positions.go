@@ -0,0 +1,307 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	stdOS "os"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/LNKLEO/OMP/regex"
+
+	json "github.com/goccy/go-json"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	toml "github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+const (
+	// the YAML errors start with their position, followed by an excerpt of the file
+	yamlErrorRegex = `^\[(?P<LINE>\d+):(?P<COLUMN>\d+)\] (?P<MESSAGE>[^\n]*)`
+)
+
+type position struct {
+	line   int
+	column int
+}
+
+// positions maps a config path like blocks[0].segments[1].type
+// to the place where it's defined in the config file
+type positions map[string]position
+
+func indexPositions(configFile string) positions {
+	index := make(positions)
+
+	data, err := stdOS.ReadFile(configFile)
+	if err != nil {
+		return index
+	}
+
+	switch configFormat(configFile) {
+	case JSON:
+		data = blankComments(data)
+		fallthrough
+	case YAML:
+		// JSON is a subset of YAML, so one parser covers both
+		file, err := parser.ParseBytes(data, 0)
+		if err != nil {
+			return index
+		}
+
+		for _, doc := range file.Docs {
+			index.yamlNode("", doc.Body)
+		}
+	case TOML:
+		index.toml(data)
+	}
+
+	return index
+}
+
+func joinPath(parent, key string) string {
+	if len(parent) == 0 {
+		return key
+	}
+
+	return parent + "." + key
+}
+
+func (p positions) yamlNode(path string, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			p.yamlNode(path, value)
+		}
+	case *ast.MappingValueNode:
+		token := n.Key.GetToken()
+		key := joinPath(path, token.Value)
+		p[key] = position{line: token.Position.Line, column: token.Position.Column}
+		p.yamlNode(key, n.Value)
+	case *ast.SequenceNode:
+		for i, value := range n.Values {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			if token := value.GetToken(); token != nil {
+				p[key] = position{line: token.Position.Line, column: token.Position.Column}
+			}
+
+			p.yamlNode(key, value)
+		}
+	case *ast.AnchorNode:
+		p.yamlNode(path, n.Value)
+	case *ast.TagNode:
+		p.yamlNode(path, n.Value)
+	}
+}
+
+func (p positions) toml(data []byte) {
+	var tomlParser unstable.Parser
+	tomlParser.Reset(data)
+
+	// the amount of entries per array of tables, needed to resolve [[blocks.segments]]
+	arrayTables := make(map[string]int)
+	var table string
+
+	// resolve turns a table header into a path, pointing into the latest entry of every array of tables,
+	// it also returns the last key node to determine the position
+	resolve := func(keys unstable.Iterator, isArrayTable bool) (string, *unstable.Node) {
+		var path string
+		var last *unstable.Node
+
+		for keys.Next() {
+			last = keys.Node()
+			path = joinPath(path, string(last.Data))
+
+			if keys.IsLast() && isArrayTable {
+				index := arrayTables[path]
+				arrayTables[path]++
+				path = fmt.Sprintf("%s[%d]", path, index)
+				continue
+			}
+
+			if count, ok := arrayTables[path]; ok {
+				path = fmt.Sprintf("%s[%d]", path, count-1)
+			}
+		}
+
+		return path, last
+	}
+
+	for tomlParser.NextExpression() {
+		expression := tomlParser.Expression()
+
+		switch expression.Kind {
+		case unstable.Table:
+			table, _ = resolve(expression.Key(), false)
+		case unstable.ArrayTable:
+			var last *unstable.Node
+			table, last = resolve(expression.Key(), true)
+			p.tomlPosition(&tomlParser, table, last)
+		case unstable.KeyValue:
+			p.tomlKeyValue(&tomlParser, table, expression)
+		}
+	}
+}
+
+func (p positions) tomlKeyValue(tomlParser *unstable.Parser, parent string, node *unstable.Node) {
+	path := parent
+	var last *unstable.Node
+
+	keys := node.Key()
+	for keys.Next() {
+		last = keys.Node()
+		path = joinPath(path, string(last.Data))
+	}
+
+	p.tomlPosition(tomlParser, path, last)
+	p.tomlValue(tomlParser, path, node.Value())
+}
+
+func (p positions) tomlValue(tomlParser *unstable.Parser, path string, node *unstable.Node) {
+	switch node.Kind {
+	case unstable.InlineTable:
+		children := node.Children()
+		for children.Next() {
+			p.tomlKeyValue(tomlParser, path, children.Node())
+		}
+	case unstable.Array:
+		children := node.Children()
+		for i := 0; children.Next(); i++ {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			child := children.Node()
+			p.tomlPosition(tomlParser, key, child)
+			p.tomlValue(tomlParser, key, child)
+		}
+	}
+}
+
+func (p positions) tomlPosition(tomlParser *unstable.Parser, path string, node *unstable.Node) {
+	if node == nil || node.Raw.Length == 0 {
+		return
+	}
+
+	shape := tomlParser.Shape(node.Raw)
+	p[path] = position{line: shape.Start.Line, column: shape.Start.Column}
+}
+
+// FileError is a problem decoding a config file, with the position it was found at when the decoder reports it
+type FileError struct {
+	Err     error
+	File    string
+	Message string
+	Line    int
+	Column  int
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// positionedError adds the file and position to an error decoding data, the content of the config file
+func positionedError(configFile string, data []byte, err error) error {
+	fileError := &FileError{
+		Err:     err,
+		File:    configFile,
+		Message: err.Error(),
+	}
+
+	var tomlError *toml.DecodeError
+	var jsonSyntaxError *json.SyntaxError
+	var jsonTypeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &tomlError):
+		fileError.Line, fileError.Column = tomlError.Position()
+	case errors.As(err, &jsonSyntaxError):
+		fileError.Line, fileError.Column = offsetPosition(data, jsonSyntaxError.Offset)
+	case errors.As(err, &jsonTypeError):
+		fileError.Line, fileError.Column = offsetPosition(data, jsonTypeError.Offset)
+	case configFormat(configFile) == YAML:
+		match := regex.FindNamedRegexMatch(yamlErrorRegex, err.Error())
+		if len(match) == 0 {
+			break
+		}
+
+		fileError.Line, _ = strconv.Atoi(match["LINE"])
+		fileError.Column, _ = strconv.Atoi(match["COLUMN"])
+		fileError.Message = match["MESSAGE"]
+	}
+
+	return fileError
+}
+
+// offsetPosition returns the line and column of the byte offset in data
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset < 0 || offset > int64(len(data)) {
+		return 0, 0
+	}
+
+	before := data[:offset]
+	start := bytes.LastIndexByte(before, '\n') + 1
+
+	return bytes.Count(before, []byte("\n")) + 1, utf8.RuneCount(before[start:]) + 1
+}
+
+// blankComments replaces the comments in JSONC with spaces,
+// unlike removing them this keeps every position in the file intact
+func blankComments(data []byte) []byte {
+	result := bytes.Clone(data)
+
+	var inString, escaped bool
+
+	for i := 0; i < len(result); i++ {
+		char := result[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case char == '\\':
+				escaped = true
+			case char == '"':
+				inString = false
+			}
+
+			continue
+		}
+
+		if char == '"' {
+			inString = true
+			continue
+		}
+
+		if char != '/' || i+1 == len(result) {
+			continue
+		}
+
+		end := len(result)
+
+		switch result[i+1] {
+		case '/':
+			if index := bytes.IndexByte(result[i:], '\n'); index != -1 {
+				end = i + index
+			}
+		case '*':
+			if index := bytes.Index(result[i+2:], []byte("*/")); index != -1 {
+				end = i + 2 + index + 2
+			}
+		default:
+			continue
+		}
+
+		for ; i < end; i++ {
+			if result[i] != '\n' {
+				result[i] = ' '
+			}
+		}
+
+		i--
+	}
+
+	return result
+}
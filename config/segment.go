@@ -3,6 +3,8 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	stdOS "os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
@@ -46,6 +48,8 @@ type Segment struct {
 	Alias                  string         `json:"alias,omitempty" toml:"alias,omitempty"`
 	styleCache             SegmentStyle
 	name                   string
+	watchHash              string
+	Placeholder            string         `json:"placeholder,omitempty" toml:"placeholder,omitempty"`
 	LeadingDiamond         string         `json:"leading_diamond,omitempty" toml:"leading_diamond,omitempty"`
 	TrailingDiamond        string         `json:"trailing_diamond,omitempty" toml:"trailing_diamond,omitempty"`
 	Template               string         `json:"template,omitempty" toml:"template,omitempty"`
@@ -66,14 +70,18 @@ type Segment struct {
 	Needs                  []string       `json:"-" toml:"-"`
 	MinWidth               int            `json:"min_width,omitempty" toml:"min_width,omitempty"`
 	MaxWidth               int            `json:"max_width,omitempty" toml:"max_width,omitempty"`
+	Priority               int            `json:"priority,omitempty" toml:"priority,omitempty"`
 	Timeout                time.Duration  `json:"timeout,omitempty" toml:"timeout,omitempty"`
 	Duration               time.Duration  `json:"-" toml:"-"`
 	NameLength             int            `json:"-" toml:"-"`
 	Interactive            bool           `json:"interactive,omitempty" toml:"interactive,omitempty"`
+	Overrun                bool           `json:"-" toml:"-"`
+	Async                  bool           `json:"async,omitempty" toml:"async,omitempty"`
 	Enabled                bool           `json:"-" toml:"-"`
 	Newline                bool           `json:"newline,omitempty" toml:"newline,omitempty"`
 	InvertPowerline        bool           `json:"invert_powerline,omitempty" toml:"invert_powerline,omitempty"`
 	restored               bool           `json:"-" toml:"-"`
+	pending                bool
 }
 
 func (segment *Segment) Name() string {
@@ -91,18 +99,18 @@ func (segment *Segment) Name() string {
 }
 
 func (segment *Segment) Execute(env runtime.Environment) {
-	// segment timings for debug purposes
-	var start time.Time
+	defer log.Trace(time.Now(), segment.Name())
+
+	// segment timings for debug purposes and structured output
+	start := time.Now()
+	defer func() {
+		segment.Duration = time.Since(start)
+	}()
+
 	if env.Flags().Debug {
-		start = time.Now()
 		segment.NameLength = len(segment.Name())
-		defer func() {
-			segment.Duration = time.Since(start)
-		}()
 	}
 
-	defer segment.evaluateNeeds()
-
 	err := segment.MapSegmentWithWriter(env)
 	if err != nil || !segment.shouldIncludeFolder() {
 		return
@@ -118,26 +126,16 @@ func (segment *Segment) Execute(env runtime.Environment) {
 		return
 	}
 
+	if segment.restoreAsync() {
+		return
+	}
+
 	if shouldHideForWidth(segment.env, segment.MinWidth, segment.MaxWidth) {
 		return
 	}
 
-	if segment.Timeout == 0 {
-		segment.Enabled = segment.writer.Enabled()
-	} else {
-		done := make(chan bool)
-		go func() {
-			segment.Enabled = segment.writer.Enabled()
-			done <- true
-		}()
-
-		select {
-		case <-done:
-			// Completed before timeout
-		case <-time.After(segment.Timeout * time.Millisecond):
-			log.Debugf("timeout after %dms for segment: %s", segment.Timeout, segment.Name())
-			return
-		}
+	if !segment.execute() {
+		return
 	}
 
 	if segment.Enabled {
@@ -146,6 +144,8 @@ func (segment *Segment) Execute(env runtime.Environment) {
 }
 
 func (segment *Segment) Render(index int) bool {
+	defer segment.setLast()
+
 	if !segment.Enabled {
 		return false
 	}
@@ -169,6 +169,11 @@ func (segment *Segment) Render(index int) bool {
 	return true
 }
 
+// Writer returns the writer backing the segment, it holds the data available to the segment's template
+func (segment *Segment) Writer() SegmentWriter {
+	return segment.writer
+}
+
 func (segment *Segment) Text() string {
 	return segment.writer.Text()
 }
@@ -245,6 +250,12 @@ func (segment *Segment) restoreCache() bool {
 		return false
 	}
 
+	// hash the files before the segment is executed, so changes made
+	// while executing invalidate the value on the next prompt
+	if segment.watchesFiles() {
+		segment.watchHash = cache.HashFiles(segment.cacheRoot(), segment.Cache.WatchFiles)
+	}
+
 	cacheKey := segment.cacheKey()
 	data, OK := segment.env.Session().Get(cacheKey)
 	if !OK {
@@ -252,6 +263,16 @@ func (segment *Segment) restoreCache() bool {
 		return false
 	}
 
+	if segment.watchesFiles() {
+		var watched watchedCache
+		if err := json.Unmarshal([]byte(data), &watched); err != nil || watched.Hash != segment.watchHash {
+			log.Debugf("watched files changed for segment: %s, key: %s", segment.Name(), cacheKey)
+			return false
+		}
+
+		data = string(watched.Writer)
+	}
+
 	err := json.Unmarshal([]byte(data), &segment.writer)
 	if err != nil {
 		log.Error(err)
@@ -278,12 +299,76 @@ func (segment *Segment) setCache() {
 		return
 	}
 
+	if segment.watchesFiles() {
+		data, err = json.Marshal(&watchedCache{
+			Hash:   segment.watchHash,
+			Writer: data,
+		})
+
+		if err != nil {
+			log.Error(err)
+			return
+		}
+	}
+
 	segment.env.Session().Set(segment.cacheKey(), string(data), segment.Cache.Duration)
 }
 
+// watchedCache is the cached value of a segment that watches files,
+// Hash identifies the state of those files when the value was stored
+type watchedCache struct {
+	Hash   string          `json:"hash"`
+	Writer json.RawMessage `json:"writer"`
+}
+
+func (segment *Segment) watchesFiles() bool {
+	return len(segment.Cache.WatchFiles) != 0
+}
+
+// cacheRoot is the folder the watched files are relative to. Segments with their own cache key,
+// like the scm segments, point to the repository's metadata as <path>@<ref>, in that case
+// it's the folder containing it, the root of the working tree. Otherwise it's the current folder.
+func (segment *Segment) cacheRoot() string {
+	pwd := segment.env.Pwd()
+
+	ctx, ok := segment.writer.(cache.Context)
+	if !ok {
+		return pwd
+	}
+
+	key, ok := ctx.CacheKey()
+	if !ok {
+		return pwd
+	}
+
+	// both the path and the ref can contain an @, so drop the parts after one until the path exists
+	for len(key) != 0 {
+		if _, err := stdOS.Stat(key); err == nil {
+			return filepath.Dir(key)
+		}
+
+		index := strings.LastIndex(key, "@")
+		if index <= 0 {
+			break
+		}
+
+		key = key[:index]
+	}
+
+	return pwd
+}
+
 func (segment *Segment) cacheKey() string {
-	format := "segment_cache_%s"
-	switch segment.Cache.Strategy {
+	return segment.key("segment_cache_%s")
+}
+
+func (segment *Segment) key(format string) string {
+	var strategy cache.Strategy
+	if segment.Cache != nil {
+		strategy = segment.Cache.Strategy
+	}
+
+	switch strategy {
 	case cache.Session:
 		return fmt.Sprintf(format, segment.Name())
 	case cache.Folder:
@@ -307,6 +392,20 @@ func (segment *Segment) folderKey() string {
 }
 
 func (segment *Segment) string() string {
+	if segment.pending {
+		tmpl := &template.Text{
+			Template: segment.Placeholder,
+			Context:  segment.writer,
+		}
+
+		text, err := tmpl.Render()
+		if err != nil {
+			return err.Error()
+		}
+
+		return text
+	}
+
 	result := segment.Templates.Resolve(segment.writer, "", segment.TemplatesLogic)
 	if len(result) != 0 {
 		return result
@@ -352,7 +451,9 @@ func (segment *Segment) cwdExcluded() bool {
 	return segment.env.DirMatchesOneOf(segment.env.Pwd(), segment.ExcludeFolders)
 }
 
-func (segment *Segment) evaluateNeeds() {
+// ResolveNeeds sets the names of the segments this segment references in its templates,
+// those need to be executed before this segment can render
+func (segment *Segment) ResolveNeeds() {
 	value := segment.Template
 
 	if len(segment.ForegroundTemplates) != 0 {
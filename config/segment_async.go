@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/json"
+	stdOS "os"
+	"strconv"
+	"time"
+
+	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/log"
+	"github.com/LNKLEO/OMP/runtime"
+	"github.com/LNKLEO/OMP/runtime/cmd"
+	"github.com/LNKLEO/OMP/template"
+)
+
+const (
+	// asyncDuration is how long the last value of an async segment can be shown
+	asyncDuration = cache.ONEWEEK
+	// asyncPendingDuration avoids starting a new refresh while one is running,
+	// unless that one didn't finish in time
+	asyncPendingDuration = cache.Duration("1m")
+)
+
+// storedValue is the last value computed for a segment, shown by async segments
+// and segments that don't execute within the render budget
+type storedValue struct {
+	Writer    json.RawMessage `json:"writer,omitempty"`
+	Refreshed int64           `json:"refreshed"`
+	Enabled   bool            `json:"enabled"`
+}
+
+func (segment *Segment) asyncKey() string {
+	return segment.key("segment_async_%s")
+}
+
+func (segment *Segment) asyncPendingKey() string {
+	return segment.key("segment_async_pending_%s")
+}
+
+// restoreAsync shows the last known value of an async segment, or its placeholder,
+// and starts a background process to compute the current value for the next prompt
+func (segment *Segment) restoreAsync() bool {
+	if !segment.Async {
+		return false
+	}
+
+	last, OK := segment.loadValue(segment.asyncKey())
+
+	segment.refresh(last.Refreshed)
+	segment.restored = true
+
+	if !OK {
+		log.Debug("no async value yet for segment: ", segment.Name())
+		segment.pending = len(segment.Placeholder) != 0
+		segment.Enabled = segment.pending
+		return true
+	}
+
+	segment.restoreValue(last)
+
+	log.Debug("restored async segment: ", segment.Name())
+
+	return true
+}
+
+func (segment *Segment) loadValue(key string) (*storedValue, bool) {
+	var value storedValue
+
+	data, OK := segment.env.Session().Get(key)
+	if !OK {
+		return &value, false
+	}
+
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		log.Error(err)
+		return &value, false
+	}
+
+	return &value, true
+}
+
+func (segment *Segment) restoreValue(value *storedValue) {
+	segment.Enabled = value.Enabled
+	if !segment.Enabled {
+		return
+	}
+
+	if err := json.Unmarshal(value.Writer, &segment.writer); err != nil {
+		log.Error(err)
+	}
+
+	template.Cache.AddSegmentData(segment.Name(), segment.writer)
+}
+
+// storeValue keeps the current value of the segment for the given duration
+func (segment *Segment) storeValue(key string, duration cache.Duration) {
+	value := &storedValue{
+		Refreshed: time.Now().UnixNano(),
+		Enabled:   segment.Enabled,
+	}
+
+	if segment.Enabled {
+		data, err := json.Marshal(segment.writer)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		value.Writer = data
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	segment.env.Session().Set(key, string(data), duration)
+}
+
+// refresh starts the background process for the segment, unless one is already running
+func (segment *Segment) refresh(lastRefreshed int64) {
+	flags := segment.env.Flags()
+
+	// only the prompts rendered by the shell get refreshed, a repaint shows the refreshed value
+	if !flags.SaveCache || flags.Repaint {
+		return
+	}
+
+	pendingKey := segment.asyncPendingKey()
+	if pending, OK := segment.env.Session().Get(pendingKey); OK {
+		if started, err := strconv.ParseInt(pending, 10, 64); err == nil && started > lastRefreshed {
+			log.Debug("async segment is already refreshing: ", segment.Name())
+			return
+		}
+	}
+
+	executable, err := stdOS.Executable()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	args := []string{
+		"async", segment.Name(),
+		"--shell", flags.Shell,
+		"--pwd", segment.env.Pwd(),
+		"--async-pid", strconv.Itoa(flags.AsyncPID),
+	}
+
+	if len(flags.Config) != 0 {
+		args = append(args, "--config", flags.Config)
+	}
+
+	// the background process has another parent, make sure it uses the same session
+	if err := cmd.Detach(executable, args, flags.Environ, "OMP_SESSION_ID="+flags.SessionID); err != nil {
+		log.Error(err)
+		return
+	}
+
+	segment.env.Session().Set(pendingKey, strconv.FormatInt(time.Now().UnixNano(), 10), asyncPendingDuration)
+}
+
+// Refresh computes the value of an async segment and stores it for the next prompt
+func (segment *Segment) Refresh(env runtime.Environment) {
+	defer log.Trace(time.Now(), segment.Name())
+
+	segment.Async = false
+	segment.Execute(env)
+
+	if segment.Enabled {
+		segment.Render(0)
+	}
+
+	segment.storeValue(segment.asyncKey(), asyncDuration)
+}
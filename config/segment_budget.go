@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LNKLEO/OMP/log"
+)
+
+func (segment *Segment) lastKey() string {
+	return segment.key("segment_last_%s")
+}
+
+// slowKey marks a segment that didn't execute within the render budget,
+// it's the same for every folder as it only tells whether to store the last value
+func (segment *Segment) slowKey() string {
+	return fmt.Sprintf("segment_slow_%s", segment.Name())
+}
+
+// execute resolves whether the segment is enabled, it returns false when that
+// doesn't happen within the segment's timeout or the render budget of the prompt
+func (segment *Segment) execute() bool {
+	budget := segment.env.Context()
+
+	if budget.Err() != nil {
+		segment.overrun()
+		return false
+	}
+
+	if segment.Timeout == 0 && budget.Done() == nil {
+		segment.Enabled = segment.writer.Enabled()
+		return true
+	}
+
+	ctx := budget
+	if segment.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(budget, segment.Timeout*time.Millisecond)
+		defer cancel()
+	}
+
+	// the writer keeps running when it doesn't finish in time, it can't change the segment after that
+	writer := segment.writer
+	done := make(chan bool, 1)
+
+	go func() {
+		done <- writer.Enabled()
+	}()
+
+	select {
+	case enabled := <-done:
+		// commands and file system probes stop once the budget is spent, their result can't be trusted
+		if budget.Err() == nil {
+			segment.Enabled = enabled
+			return true
+		}
+	case <-ctx.Done():
+	}
+
+	if budget.Err() != nil {
+		segment.overrun()
+		return false
+	}
+
+	log.Debugf("timeout after %dms for segment: %s", segment.Timeout, segment.Name())
+	return false
+}
+
+// overrun shows the last value of a segment that didn't execute within
+// the render budget, or its placeholder when there is none
+func (segment *Segment) overrun() {
+	log.Debugf("render budget exceeded for segment: %s", segment.Name())
+
+	segment.Overrun = true
+	segment.restored = true
+
+	segment.env.Session().Set(segment.slowKey(), "true", asyncDuration)
+
+	// the writer that ran out of time could still change
+	if err := segment.MapSegmentWithWriter(segment.env); err != nil {
+		return
+	}
+
+	if last, OK := segment.loadValue(segment.lastKey()); OK {
+		segment.restoreValue(last)
+		return
+	}
+
+	segment.pending = len(segment.Placeholder) != 0
+	segment.Enabled = segment.pending
+}
+
+// setLast stores the value of a segment that didn't execute within the render budget before,
+// it's shown when that happens again on one of the next prompts
+func (segment *Segment) setLast() {
+	if segment.restored || segment.env == nil || segment.writer == nil {
+		return
+	}
+
+	if segment.env.Flags().RenderBudget == 0 {
+		return
+	}
+
+	if _, slow := segment.env.Session().Get(segment.slowKey()); !slow {
+		return
+	}
+
+	segment.storeValue(segment.lastKey(), asyncDuration)
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"github.com/LNKLEO/OMP/properties"
+	"github.com/LNKLEO/OMP/segments"
+)
+
+var (
+	languageProperties = []properties.Property{
+		properties.FetchVersion,
+		properties.VersionURLTemplate,
+		properties.CacheDuration,
+		segments.DisplayMode,
+		segments.HomeEnabled,
+		segments.LanguageExtensions,
+		segments.LanguageFolders,
+		segments.MissingCommandText,
+	}
+
+	scmProperties = []properties.Property{
+		segments.FetchStatus,
+		segments.StatusFormats,
+		segments.NativeFallback,
+		segments.BranchTemplate,
+		segments.MappedBranches,
+	}
+)
+
+// SegmentProperties contains the properties every segment type understands.
+// A segment type that is missing from the list accepts any property.
+var SegmentProperties = map[SegmentType][]properties.Property{
+	ANGULAR: languageProperties,
+	ARGOCD:  {},
+	AURELIA: languageProperties,
+	AWS:     {properties.DisplayDefault},
+	AZ:      {segments.Source},
+	AZD:     {segments.LanguageFolders},
+	AZFUNC:  languageProperties,
+	BATTERY: {
+		properties.DisplayError,
+		segments.ChargingIcon,
+		segments.DischargingIcon,
+		segments.ChargedIcon,
+		segments.NotChargingIcon,
+	},
+	BAZEL:           append([]properties.Property{segments.Icon}, languageProperties...),
+	BUF:             languageProperties,
+	CARBONINTENSITY: {properties.HTTPTimeout},
+	CMAKE:           languageProperties,
+	CMD: {
+		segments.ExecutableShell,
+		segments.Command,
+		segments.Script,
+		segments.Interpret,
+	},
+	CONNECTION:    {segments.Type},
+	DOCKER:        {},
+	DOTNET:        languageProperties,
+	EXECUTIONTIME: {properties.AlwaysEnabled, properties.Style, segments.ThresholdProperty},
+	EXIT:          {properties.AlwaysEnabled, segments.StatusTemplate, segments.StatusSeparator},
+	GCP:           {},
+	GIT: append([]properties.Property{
+		segments.IgnoreStatus,
+		segments.FetchStashCount,
+		segments.FetchWorktreeCount,
+		segments.FetchUpstreamIcon,
+		segments.FetchBareInfo,
+		segments.FetchUser,
+		segments.BranchIcon,
+		segments.BranchIdenticalIcon,
+		segments.BranchAheadIcon,
+		segments.BranchBehindIcon,
+		segments.BranchGoneIcon,
+		segments.RebaseIcon,
+		segments.CherryPickIcon,
+		segments.RevertIcon,
+		segments.CommitIcon,
+		segments.NoCommitsIcon,
+		segments.TagIcon,
+		segments.MergeIcon,
+		segments.UpstreamIcons,
+		segments.GithubIcon,
+		segments.AzureDevOpsIcon,
+		segments.GitIcon,
+		segments.UntrackedModes,
+		segments.IgnoreSubmodules,
+	}, scmProperties...),
+	GITVERSION: {},
+	GOLANG:     append([]properties.Property{segments.ParseModFile, segments.ParseWorkFile}, languageProperties...),
+	HASKELL:    append([]properties.Property{segments.StackGhcMode}, languageProperties...),
+	HELM:       {segments.DisplayMode},
+	JAVA:       languageProperties,
+	KOTLIN:     languageProperties,
+	KUBECTL:    {properties.DisplayError, segments.ParseKubeConfig, segments.ContextAliases},
+	LUA:        append([]properties.Property{segments.PreferredExecutable}, languageProperties...),
+	MERCURIAL:  scmProperties,
+	MOJO:       append([]properties.Property{properties.DisplayDefault, segments.FetchVirtualEnv}, languageProperties...),
+	MVN:        languageProperties,
+	NETWORKS: {
+		properties.DisplayError,
+		"Spliter",
+		"IconAsAT",
+		"ShowType",
+		"ShowSSID",
+		"SSIDAbbr",
+		"LinkSpeedFull",
+		"LinkSpeedUnit",
+	},
+	NODE: append([]properties.Property{
+		segments.PnpmIcon,
+		segments.YarnIcon,
+		segments.NPMIcon,
+		segments.FetchPackageManager,
+	}, languageProperties...),
+	NPM: languageProperties,
+	PATH: {
+		properties.Style,
+		segments.FolderSeparatorIcon,
+		segments.FolderSeparatorTemplate,
+		segments.HomeIcon,
+		segments.FolderIcon,
+		segments.WindowsRegistryIcon,
+		segments.MixedThreshold,
+		segments.MappedLocations,
+		segments.MappedLocationsEnabled,
+		segments.MaxDepth,
+		segments.MaxWidth,
+		segments.HideRootLocation,
+		segments.Cycle,
+		segments.CycleFolderSeparator,
+		segments.FolderFormat,
+		segments.EdgeFormat,
+		segments.LeftFormat,
+		segments.RightFormat,
+		segments.GitDirFormat,
+	},
+	PROJECT: {properties.AlwaysEnabled},
+	PYTHON: append([]properties.Property{
+		properties.DisplayDefault,
+		segments.FetchVirtualEnv,
+		segments.UsePythonVersionFile,
+		segments.FolderNameFallback,
+		segments.DefaultVenvNames,
+	}, languageProperties...),
+	QUASAR:     append([]properties.Property{segments.FetchDependencies}, languageProperties...),
+	REACT:      languageProperties,
+	ROOT:       {},
+	RUST:       languageProperties,
+	SESSION:    {},
+	SHELL:      {segments.MappedShellNames},
+	STATUS:     {properties.AlwaysEnabled, segments.StatusTemplate, segments.StatusSeparator},
+	SVN:        scmProperties,
+	SYSTEMINFO: {segments.Precision},
+	TALOSCTL:   {},
+	TEXT:       {},
+	TIME:       {segments.TimeFormat},
+	WINREG:     {segments.RegistryPath, segments.Fallback},
+	XMAKE:      languageProperties,
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	stdOS "os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/log"
+)
+
+func init() {
+	// values decoded into an any field, like properties and Var, need to be known by gob
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+// snapshot is the decoded config stored in the cache folder,
+// it remains valid as long as none of the files it was created from changed
+// and the executable that created it is the same
+type snapshot struct {
+	Config     *Config
+	Extended   []string
+	Executable string
+	Files      []snapshotFile
+}
+
+type snapshotFile struct {
+	Path    string
+	Size    int64
+	ModTime int64
+}
+
+func newSnapshotFile(path string) (snapshotFile, error) {
+	info, err := stdOS.Stat(path)
+	if err != nil {
+		return snapshotFile{}, err
+	}
+
+	return snapshotFile{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+	}, nil
+}
+
+// executableVersion identifies the running binary, as the Config layout can change between builds
+func executableVersion() string {
+	executable, err := stdOS.Executable()
+	if err != nil {
+		return ""
+	}
+
+	info, err := stdOS.Stat(executable)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d.%d.%d", Version, info.Size(), info.ModTime().UnixNano())
+}
+
+func snapshotPath(configFile string) string {
+	sum := sha256.Sum256([]byte(configFile))
+	return filepath.Join(cache.Path(), fmt.Sprintf("%s.config.%s.gob", cache.FileName, hex.EncodeToString(sum[:8])))
+}
+
+func snapshotDisabled() bool {
+	return stdOS.Getenv("OMP_CACHE_DISABLED") == "1"
+}
+
+// warmSnapshots holds the encoded snapshots per config file, see KeepWarm
+var warmSnapshots map[string][]byte
+
+// KeepWarm keeps the decoded configs in memory, for a process that renders more than one prompt.
+// Every Load still returns a new copy, as rendering changes the config.
+func KeepWarm() {
+	warmSnapshots = make(map[string][]byte)
+}
+
+func loadSnapshot(configFile string) (*Config, bool) {
+	defer log.Trace(time.Now(), configFile)
+
+	if data, ok := warmSnapshots[configFile]; ok {
+		if cfg, ok := decodeSnapshot(configFile, data); ok {
+			return cfg, true
+		}
+
+		delete(warmSnapshots, configFile)
+	}
+
+	if snapshotDisabled() {
+		return nil, false
+	}
+
+	data, err := stdOS.ReadFile(snapshotPath(configFile))
+	if err != nil {
+		return nil, false
+	}
+
+	cfg, ok := decodeSnapshot(configFile, data)
+	if ok && warmSnapshots != nil {
+		warmSnapshots[configFile] = data
+	}
+
+	return cfg, ok
+}
+
+func decodeSnapshot(configFile string, data []byte) (*Config, bool) {
+	var snap snapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+		log.Error(err)
+		return nil, false
+	}
+
+	if snap.Config == nil || snap.Executable != executableVersion() {
+		return nil, false
+	}
+
+	if len(snap.Files) == 0 || snap.Files[0].Path != configFile {
+		return nil, false
+	}
+
+	for _, file := range snap.Files {
+		current, err := newSnapshotFile(file.Path)
+		if err != nil || current != file {
+			log.Debug("config snapshot is outdated:", file.Path)
+			return nil, false
+		}
+	}
+
+	cfg := snap.Config
+	cfg.origin = configFile
+	cfg.extended = snap.Extended
+
+	log.Debug("config restored from snapshot")
+	return cfg, true
+}
+
+func saveSnapshot(cfg *Config) {
+	defer log.Trace(time.Now())
+
+	if len(cfg.origin) == 0 {
+		return
+	}
+
+	snap := snapshot{
+		Config:     cfg,
+		Extended:   cfg.extended,
+		Executable: executableVersion(),
+	}
+
+	for _, path := range slices.Concat([]string{cfg.origin}, cfg.extended) {
+		file, err := newSnapshotFile(path)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		snap.Files = append(snap.Files, file)
+	}
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(&snap); err != nil {
+		log.Error(err)
+		return
+	}
+
+	if warmSnapshots != nil {
+		warmSnapshots[cfg.origin] = buffer.Bytes()
+	}
+
+	if snapshotDisabled() {
+		return
+	}
+
+	if err := stdOS.WriteFile(snapshotPath(cfg.origin), buffer.Bytes(), 0o644); err != nil {
+		log.Error(err)
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// source is the file, and the path in it, that defines a value of a merged config
+type source struct {
+	file string
+	path string
+}
+
+type sourceKey struct {
+	object uintptr
+	key    string
+}
+
+// sources remembers which file of an extends chain defines the values of the merged config.
+// Merging moves values between the raw documents, so they're identified by the map that holds them.
+type sources struct {
+	objects map[uintptr]source
+	keys    map[sourceKey]source
+}
+
+func objectID(object map[string]any) uintptr {
+	return reflect.ValueOf(object).Pointer()
+}
+
+// add records the file as the source of value and everything it contains
+func (s *sources) add(file, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		s.objects[objectID(v)] = source{file: file, path: path}
+
+		for key, child := range v {
+			childPath := joinPath(path, key)
+			s.keys[sourceKey{object: objectID(v), key: key}] = source{file: file, path: childPath}
+			s.add(file, childPath, child)
+		}
+	case []any:
+		for i, child := range v {
+			s.add(file, fmt.Sprintf("%s[%d]", path, i), child)
+		}
+	}
+}
+
+// moved records that key in target now holds the value the child document defined
+func (s *sources) moved(target, child map[string]any, key string) {
+	if origin, ok := s.keys[sourceKey{object: objectID(child), key: key}]; ok {
+		s.keys[sourceKey{object: objectID(target), key: key}] = origin
+	}
+}
+
+// lookup follows a path like blocks[0].segments[1].type through the merged document
+// and returns the file and the path in it that defines the value
+func (s *sources) lookup(raw map[string]any, path string) (source, bool) {
+	origin, ok := s.objects[objectID(raw)]
+	if !ok {
+		return origin, false
+	}
+
+	var current any = raw
+
+	for _, part := range strings.Split(path, ".") {
+		key, indexes, _ := strings.Cut(part, "[")
+
+		object, ok := current.(map[string]any)
+		if !ok {
+			return origin, false
+		}
+
+		if keyOrigin, ok := s.keys[sourceKey{object: objectID(object), key: key}]; ok {
+			origin = keyOrigin
+		} else {
+			origin.path = joinPath(origin.path, key)
+		}
+
+		current = object[key]
+
+		if len(indexes) == 0 {
+			continue
+		}
+
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			i, err := strconv.Atoi(index)
+			list, ok := current.([]any)
+
+			if err != nil || !ok || i < 0 || i >= len(list) {
+				return origin, false
+			}
+
+			current = list[i]
+
+			if object, ok := current.(map[string]any); ok {
+				if objectOrigin, ok := s.objects[objectID(object)]; ok {
+					origin = objectOrigin
+					continue
+				}
+			}
+
+			origin.path = fmt.Sprintf("%s[%d]", origin.path, i)
+		}
+	}
+
+	return origin, true
+}
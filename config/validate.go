@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/LNKLEO/OMP/color"
+	"github.com/LNKLEO/OMP/regex"
+	"github.com/LNKLEO/OMP/template"
+)
+
+const (
+	paletteReferenceRegex = `(?:^|[<,\s"'}])p:(?P<KEY>[\w\-.]+)`
+)
+
+// Problem is a single issue found while validating a config file
+type Problem struct {
+	File    string
+	Path    string
+	Message string
+	Line    int
+	Column  int
+}
+
+func (p *Problem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location += fmt.Sprintf(":%d:%d", p.Line, p.Column)
+	}
+
+	if len(p.Path) == 0 {
+		return fmt.Sprintf("%s: %s", location, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", location, p.Path, p.Message)
+}
+
+type validator struct {
+	cfg        *Config
+	raw        map[string]any
+	sources    *sources
+	positions  map[string]positions
+	configFile string
+	palettes   []color.Palette
+	problems   []*Problem
+}
+
+// Validate parses the config file and returns every problem found in it,
+// an empty list means the config is valid
+func Validate(configFile string) []*Problem {
+	cfg, err := Parse(configFile)
+	if err != nil {
+		return []*Problem{parseProblem(configFile, err)}
+	}
+
+	v := &validator{
+		cfg:        cfg,
+		configFile: configFile,
+		positions:  make(map[string]positions),
+		palettes:   []color.Palette{cfg.Palette},
+	}
+
+	// report the problems in the config that defines the value, not in the one extending it
+	if len(cfg.extended) != 0 {
+		v.raw, v.sources, err = resolveSources(configFile)
+		if err != nil {
+			return []*Problem{parseProblem(configFile, err)}
+		}
+	}
+
+	if cfg.Palettes != nil {
+		for _, palette := range cfg.Palettes.List {
+			v.palettes = append(v.palettes, palette)
+		}
+	}
+
+	v.validate()
+
+	return v.problems
+}
+
+func parseProblem(configFile string, err error) *Problem {
+	problem := &Problem{
+		File:    configFile,
+		Message: err.Error(),
+	}
+
+	var fileError *FileError
+	if errors.As(err, &fileError) {
+		problem.File = fileError.File
+		problem.Message = fileError.Message
+		problem.Line = fileError.Line
+		problem.Column = fileError.Column
+	}
+
+	return problem
+}
+
+func (v *validator) add(path, format string, args ...any) {
+	problem := &Problem{
+		File:    v.configFile,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	if v.sources != nil {
+		if origin, ok := v.sources.lookup(v.raw, path); ok {
+			problem.File = origin.file
+			problem.Path = origin.path
+		}
+	}
+
+	index, ok := v.positions[problem.File]
+	if !ok {
+		index = indexPositions(problem.File)
+		v.positions[problem.File] = index
+	}
+
+	if pos, ok := index[problem.Path]; ok {
+		problem.Line = pos.line
+		problem.Column = pos.column
+	}
+
+	v.problems = append(v.problems, problem)
+}
+
+func (v *validator) validate() {
+	v.template("console_title_template", v.cfg.ConsoleTitleTemplate)
+	v.color("terminal_background", v.cfg.TerminalBackground)
+	v.color("accent_color", v.cfg.AccentColor)
+
+	if v.cfg.Palettes != nil {
+		v.template("palettes.template", v.cfg.Palettes.Template)
+	}
+
+	for i, block := range v.cfg.Blocks {
+		v.block(fmt.Sprintf("blocks[%d]", i), block)
+	}
+
+	for i, tooltip := range v.cfg.Tooltips {
+		v.segment(fmt.Sprintf("tooltips[%d]", i), tooltip)
+	}
+
+	extraPrompts := map[string]*Segment{
+		"debug_prompt":     v.cfg.DebugPrompt,
+		"valid_line":       v.cfg.ValidLine,
+		"secondary_prompt": v.cfg.SecondaryPrompt,
+		"transient_prompt": v.cfg.TransientPrompt,
+		"error_line":       v.cfg.ErrorLine,
+	}
+
+	for _, path := range sortedKeys(extraPrompts) {
+		if extraPrompt := extraPrompts[path]; extraPrompt != nil {
+			v.segmentTemplates(path, extraPrompt)
+		}
+	}
+}
+
+func (v *validator) block(path string, block *Block) {
+	if block == nil {
+		return
+	}
+
+	switch block.Type {
+	case "", Prompt, RPrompt:
+	default:
+		v.add(path+".type", "invalid block type %q, expected one of: %s, %s", block.Type, Prompt, RPrompt)
+	}
+
+	switch block.Alignment {
+	case "", Left, Right:
+	default:
+		v.add(path+".alignment", "invalid block alignment %q, expected one of: %s, %s", block.Alignment, Left, Right)
+	}
+
+	switch block.Overflow {
+	case "", Break, Hide, Truncate:
+	default:
+		v.add(path+".overflow", "invalid block overflow %q, expected one of: %s, %s, %s", block.Overflow, Break, Hide, Truncate)
+	}
+
+	switch block.TruncateSide {
+	case "", TruncateStart, TruncateEnd:
+	default:
+		v.add(path+".truncate_side", "invalid block truncate side %q, expected one of: %s, %s", block.TruncateSide, TruncateStart, TruncateEnd)
+	}
+
+	for i, segment := range block.Segments {
+		v.segment(fmt.Sprintf("%s.segments[%d]", path, i), segment)
+	}
+}
+
+func (v *validator) segment(path string, segment *Segment) {
+	if segment == nil {
+		return
+	}
+
+	if _, ok := Segments[segment.Type]; !ok {
+		if len(segment.Type) == 0 {
+			v.add(path, "missing segment type")
+		} else {
+			v.add(path+".type", "unknown segment type %q", segment.Type)
+		}
+	}
+
+	if known, ok := SegmentProperties[segment.Type]; ok {
+		for _, property := range sortedKeys(segment.Properties) {
+			if slices.Contains(known, property) {
+				continue
+			}
+
+			v.add(fmt.Sprintf("%s.properties.%s", path, property), "unknown property %q for segment type %s", property, segment.Type)
+		}
+	}
+
+	switch {
+	case strings.Contains(string(segment.Style), "{{"):
+		v.template(path+".style", string(segment.Style))
+	case segment.Style == "", segment.Style == Plain, segment.Style == Powerline, segment.Style == Accordion, segment.Style == Diamond:
+	default:
+		v.add(path+".style", "invalid segment style %q, expected one of: %s, %s, %s, %s", segment.Style, Plain, Powerline, Accordion, Diamond)
+	}
+
+	v.segmentTemplates(path, segment)
+}
+
+func (v *validator) segmentTemplates(path string, segment *Segment) {
+	v.template(path+".template", segment.Template)
+
+	for i, tmpl := range segment.Templates {
+		v.template(fmt.Sprintf("%s.templates[%d]", path, i), tmpl)
+	}
+
+	v.color(path+".foreground", segment.Foreground)
+	v.color(path+".background", segment.Background)
+
+	for i, tmpl := range segment.ForegroundTemplates {
+		v.template(fmt.Sprintf("%s.foreground_templates[%d]", path, i), tmpl)
+	}
+
+	for i, tmpl := range segment.BackgroundTemplates {
+		v.template(fmt.Sprintf("%s.background_templates[%d]", path, i), tmpl)
+	}
+}
+
+func (v *validator) template(path, text string) {
+	if err := template.Validate(text); err != nil {
+		v.add(path, "invalid template: %s", err)
+	}
+
+	for _, match := range regex.FindAllNamedRegexMatch(paletteReferenceRegex, text) {
+		v.paletteKey(path, match["KEY"])
+	}
+}
+
+func (v *validator) color(path string, value color.Ansi) {
+	if !strings.HasPrefix(string(value), "p:") || strings.Contains(string(value), "{{") {
+		return
+	}
+
+	v.paletteKey(path, strings.TrimPrefix(string(value), "p:"))
+}
+
+func (v *validator) paletteKey(path, key string) {
+	for _, palette := range v.palettes {
+		if _, ok := palette[color.Ansi(key)]; ok {
+			return
+		}
+	}
+
+	v.add(path, "palette color %q does not exist", key)
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	return keys
+}
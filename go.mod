@@ -19,6 +19,7 @@ require (
 )
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.10.0
 	github.com/goccy/go-json v0.10.5
 	github.com/goccy/go-yaml v1.16.0
 	github.com/gookit/goutil v0.6.18
@@ -26,6 +27,7 @@ require (
 	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/spf13/cobra v1.9.1
 	github.com/spf13/pflag v1.0.6
+	go.etcd.io/bbolt v1.4.3
 	golang.org/x/mod v0.24.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,129 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level defines which messages are written to the log file
+type Level int
+
+const (
+	ErrorLevel Level = iota
+	DebugLevel
+	TraceLevel
+)
+
+const (
+	maxFileSize    = 1024 * 1024
+	maxBackupFiles = 3
+)
+
+var sink *fileSink
+
+type fileSink struct {
+	file    *os.File
+	path    string
+	session string
+	level   Level
+	lock    sync.Mutex
+}
+
+// ParseLevel converts error, debug or trace to a Level, defaulting to ErrorLevel
+func ParseLevel(level string) Level {
+	switch strings.ToLower(level) {
+	case "trace":
+		return TraceLevel
+	case "debug":
+		return DebugLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// SetFile writes every message up to the given level to the file at path,
+// independent of Enable. The file is rotated once it grows beyond 1MB.
+func SetFile(path string, level Level, session string) {
+	sink = &fileSink{
+		path:    path,
+		level:   level,
+		session: session,
+	}
+}
+
+func writeFile(lt logType, header, message string) {
+	if sink == nil {
+		return
+	}
+
+	var level string
+	switch lt {
+	case bug:
+		level = "ERROR"
+	case debug:
+		if sink.level < DebugLevel {
+			return
+		}
+
+		level = "DEBUG"
+	case trace:
+		if sink.level < TraceLevel {
+			return
+		}
+
+		level = "TRACE"
+	}
+
+	line := fmt.Sprintf("%s [%s] [%s] %s", time.Now().Format("2006-01-02 15:04:05.000"), level, sink.session, header)
+	if len(message) != 0 {
+		line += " → " + strings.ReplaceAll(message, "\n", "\n    ")
+	}
+
+	sink.write(line + "\n")
+}
+
+func (s *fileSink) write(line string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.file == nil {
+		file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return
+		}
+
+		s.file = file
+	}
+
+	if info, err := s.file.Stat(); err == nil && info.Size()+int64(len(line)) > maxFileSize {
+		s.rotate()
+	}
+
+	if s.file == nil {
+		return
+	}
+
+	_, _ = s.file.WriteString(line)
+}
+
+// rotate moves the current file to path.1, and the existing backups one place up
+func (s *fileSink) rotate() {
+	_ = s.file.Close()
+	s.file = nil
+
+	for i := maxBackupFiles - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+
+	_ = os.Rename(s.path, s.path+".1")
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+
+	s.file = file
+}
@@ -23,30 +23,53 @@ func Plain() {
 }
 
 func Trace(start time.Time, args ...string) {
-	if !enabled {
+	toFile := sink != nil && sink.level >= TraceLevel
+	if !enabled && !recording && !toFile {
 		return
 	}
 
 	elapsed := time.Since(start)
 	fn, _ := funcSpec()
+
+	if recording {
+		record(start, elapsed, fn, args)
+	}
+
+	if toFile {
+		writeFile(trace, fmt.Sprintf("%s(%s) - %s", fn, strings.Join(args, " "), elapsed), "")
+	}
+
+	if !enabled {
+		return
+	}
+
 	header := fmt.Sprintf("%s(%s) - %s", fn, strings.Join(args, " "), Text(elapsed.String()).Yellow().Plain())
 
 	printLn(trace, header)
 }
 
 func Debug(message ...string) {
-	if !enabled {
+	toFile := sink != nil && sink.level >= DebugLevel
+	if !enabled && !toFile {
 		return
 	}
 
 	fn, line := funcSpec()
 	header := fmt.Sprintf("%s:%d", fn, line)
 
+	if toFile {
+		writeFile(debug, header, strings.Join(message, " "))
+	}
+
+	if !enabled {
+		return
+	}
+
 	printLn(debug, header, strings.Join(message, " "))
 }
 
 func Debugf(format string, args ...interface{}) {
-	if !enabled {
+	if !enabled && (sink == nil || sink.level < DebugLevel) {
 		return
 	}
 
@@ -55,12 +78,19 @@ func Debugf(format string, args ...interface{}) {
 }
 
 func Error(err error) {
-	if !enabled {
+	if !enabled && sink == nil {
 		return
 	}
+
 	fn, line := funcSpec()
 	header := fmt.Sprintf("%s:%d", fn, line)
 
+	writeFile(bug, header, err.Error())
+
+	if !enabled {
+		return
+	}
+
 	printLn(bug, header, err.Error())
 }
 
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// Call is a single function call timed using Trace
+type Call struct {
+	Start    time.Time
+	Function string
+	Args     []string
+	Duration time.Duration
+}
+
+var (
+	recording bool
+	calls     []*Call
+	callsLock sync.Mutex
+)
+
+// Record keeps every call passed to Trace, even when logging is disabled
+func Record() {
+	recording = true
+}
+
+// Calls returns the calls recorded since the last reset
+func Calls() []*Call {
+	callsLock.Lock()
+	defer callsLock.Unlock()
+
+	return append([]*Call{}, calls...)
+}
+
+// ResetCalls removes all recorded calls
+func ResetCalls() {
+	callsLock.Lock()
+	defer callsLock.Unlock()
+
+	calls = nil
+}
+
+func record(start time.Time, elapsed time.Duration, fn string, args []string) {
+	callsLock.Lock()
+	defer callsLock.Unlock()
+
+	calls = append(calls, &Call{
+		Start:    start,
+		Function: fn,
+		Args:     args,
+		Duration: elapsed,
+	})
+}
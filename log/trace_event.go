@@ -0,0 +1,132 @@
+package log
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// traceEvent is a complete event in the Chrome trace-event format,
+// see https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Args     map[string]string `json:"args,omitempty"`
+	Name     string            `json:"name"`
+	Category string            `json:"cat"`
+	Phase    string            `json:"ph"`
+	Start    int64             `json:"ts"`
+	Duration int64             `json:"dur"`
+	PID      int               `json:"pid"`
+	TID      int               `json:"tid"`
+}
+
+type traceFile struct {
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+	TraceEvents     []*traceEvent `json:"traceEvents"`
+}
+
+// WriteTrace writes the recorded calls to the given file as Chrome trace-event JSON,
+// which can be opened in chrome://tracing or Perfetto
+func WriteTrace(path string) error {
+	recorded := Calls()
+	sort.Slice(recorded, func(i, j int) bool {
+		if recorded[i].Start.Equal(recorded[j].Start) {
+			// parents before children when they start at the same time
+			return recorded[i].Duration > recorded[j].Duration
+		}
+
+		return recorded[i].Start.Before(recorded[j].Start)
+	})
+
+	trace := &traceFile{
+		DisplayTimeUnit: "ms",
+		TraceEvents:     make([]*traceEvent, 0, len(recorded)),
+	}
+
+	if len(recorded) == 0 {
+		return write(path, trace)
+	}
+
+	origin := recorded[0].Start
+	pid := os.Getpid()
+
+	// calls run concurrently, we spread them over lanes so spans on the same lane
+	// either nest or follow each other, which is what the viewers expect from a thread
+	var lanes [][]time.Time
+
+	for _, call := range recorded {
+		end := call.Start.Add(call.Duration)
+		lane := -1
+
+		for i, ends := range lanes {
+			for len(ends) > 0 && !ends[len(ends)-1].After(call.Start) {
+				ends = ends[:len(ends)-1]
+			}
+
+			lanes[i] = ends
+
+			if len(ends) == 0 || !end.After(ends[len(ends)-1]) {
+				lane = i
+				break
+			}
+		}
+
+		if lane == -1 {
+			lanes = append(lanes, nil)
+			lane = len(lanes) - 1
+		}
+
+		lanes[lane] = append(lanes[lane], end)
+
+		event := newTraceEvent(call)
+		event.Start = call.Start.Sub(origin).Microseconds()
+		event.Duration = call.Duration.Microseconds()
+		event.PID = pid
+		event.TID = lane + 1
+
+		trace.TraceEvents = append(trace.TraceEvents, event)
+	}
+
+	return write(path, trace)
+}
+
+func newTraceEvent(call *Call) *traceEvent {
+	event := &traceEvent{
+		Phase:    "X",
+		Category: "function",
+		Name:     call.Function,
+	}
+
+	args := strings.Join(call.Args, " ")
+	_, function, _ := strings.Cut(call.Function, ":")
+
+	switch function {
+	case "Execute":
+		event.Category = "segment"
+		event.Name = args
+	case "RunCommand":
+		event.Category = "command"
+		event.Name = args
+	case "Render":
+		event.Category = "template"
+		event.Name = "template"
+		event.Args = map[string]string{"template": args}
+	default:
+		if len(args) != 0 {
+			event.Args = map[string]string{"args": args}
+		}
+	}
+
+	return event
+}
+
+func write(path string, trace *traceFile) error {
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
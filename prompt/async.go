@@ -0,0 +1,18 @@
+package prompt
+
+// Refresh computes the value of the async segment with the given name,
+// it's stored in the session cache to be shown by the next prompt
+func (e *Engine) Refresh(name string) bool {
+	for _, block := range e.Config.Blocks {
+		for _, segment := range block.Segments {
+			if !segment.Async || segment.Name() != name {
+				continue
+			}
+
+			segment.Refresh(e.Env)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,175 @@
+package prompt
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LNKLEO/OMP/log"
+	"github.com/LNKLEO/OMP/runtime"
+	"github.com/LNKLEO/OMP/template"
+)
+
+const (
+	slowestCommandCount = 5
+)
+
+// BenchStats holds the latency percentiles in milliseconds
+type BenchStats struct {
+	Name string  `json:"name"`
+	P50  float64 `json:"p50_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+}
+
+type BenchRun struct {
+	Total    *BenchStats   `json:"total"`
+	Segments []*BenchStats `json:"segments"`
+}
+
+type BenchCommand struct {
+	Command string  `json:"command"`
+	Calls   int     `json:"calls"`
+	Max     float64 `json:"max_ms"`
+}
+
+type BenchResult struct {
+	Cached     *BenchRun       `json:"cached"`
+	Uncached   *BenchRun       `json:"uncached"`
+	Commands   []*BenchCommand `json:"slowest_commands"`
+	Iterations int             `json:"iterations"`
+}
+
+// Bench renders the primary prompt the given amount of times, once using the session cache
+// and once ignoring it, and reports the latency of the engine and every segment
+func Bench(flags *runtime.Flags, iterations int) *BenchResult {
+	log.Record()
+	defer log.ResetCalls()
+
+	return &BenchResult{
+		Iterations: iterations,
+		Cached:     benchRun(flags, iterations, true),
+		Uncached:   benchRun(flags, iterations, false),
+		Commands:   slowestCommands(log.Calls()),
+	}
+}
+
+func benchRun(flags *runtime.Flags, iterations int, cached bool) *BenchRun {
+	var totals []time.Duration
+	var names []string
+	segments := make(map[string][]time.Duration)
+
+	for range iterations {
+		iterationFlags := *flags
+		iterationFlags.IsPrimary = true
+		iterationFlags.SaveCache = cached
+		iterationFlags.Bench = true
+
+		start := time.Now()
+
+		eng := New(&iterationFlags)
+
+		for _, block := range eng.Config.Blocks {
+			for _, segment := range block.Segments {
+				// measure the segment itself, instead of starting a background refresh
+				segment.Async = false
+
+				if !cached {
+					segment.Cache = nil
+				}
+			}
+		}
+
+		_ = eng.Primary()
+		totals = append(totals, time.Since(start))
+
+		for _, block := range eng.Config.Blocks {
+			for _, segment := range block.Segments {
+				name := segment.Name()
+				if _, ok := segments[name]; !ok {
+					names = append(names, name)
+				}
+
+				segments[name] = append(segments[name], segment.Duration)
+			}
+		}
+
+		if cached {
+			template.SaveCache()
+		}
+
+		eng.Env.Close()
+	}
+
+	run := &BenchRun{
+		Total: newBenchStats("Total", totals),
+	}
+
+	for _, name := range names {
+		run.Segments = append(run.Segments, newBenchStats(name, segments[name]))
+	}
+
+	return run
+}
+
+func newBenchStats(name string, durations []time.Duration) *BenchStats {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	return &BenchStats{
+		Name: name,
+		P50:  percentile(durations, 50),
+		P95:  percentile(durations, 95),
+		P99:  percentile(durations, 99),
+	}
+}
+
+// percentile uses the nearest-rank method on the sorted durations
+func percentile(durations []time.Duration, p float64) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(durations))))
+	index := min(max(rank-1, 0), len(durations)-1)
+
+	return milliseconds(durations[index])
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
+
+func slowestCommands(calls []*log.Call) []*BenchCommand {
+	commands := make(map[string]*BenchCommand)
+
+	for _, call := range calls {
+		if !strings.HasSuffix(call.Function, ":RunCommand") {
+			continue
+		}
+
+		command := strings.Join(call.Args, " ")
+
+		benchCommand, ok := commands[command]
+		if !ok {
+			benchCommand = &BenchCommand{Command: command}
+			commands[command] = benchCommand
+		}
+
+		benchCommand.Calls++
+		benchCommand.Max = max(benchCommand.Max, milliseconds(call.Duration))
+	}
+
+	result := make([]*BenchCommand, 0, len(commands))
+	for _, command := range commands {
+		result = append(result, command)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Max > result[j].Max })
+
+	if len(result) > slowestCommandCount {
+		result = result[:slowestCommandCount]
+	}
+
+	return result
+}
@@ -62,10 +62,19 @@ func (e *Engine) PrintDebug(startTime time.Time) string {
 			active = log.Text("false").Purple()
 		}
 		segmentName := fmt.Sprintf("%s(%s)", segment.Name(), active.Plain())
-		e.write(fmt.Sprintf("%-*s - %3d ms\n", largestSegmentNameLength, segmentName, duration))
+		line := fmt.Sprintf("%-*s - %3d ms", largestSegmentNameLength, segmentName, duration)
+		if segment.Overrun {
+			line += log.Text(" (render budget exceeded)").Red().Plain().String()
+		}
+
+		e.write(line + "\n")
 	}
 
 	e.write(fmt.Sprintf("\n%s %s\n", log.Text("Run duration:").Green().Bold().Plain(), time.Since(startTime)))
+
+	if budget := e.Env.Flags().RenderBudget; budget > 0 {
+		e.write(fmt.Sprintf("\n%s %d ms\n", log.Text("Render budget:").Green().Bold().Plain(), budget))
+	}
 	e.write(fmt.Sprintf("\n%s %s\n", log.Text("Cache path:").Green().Bold().Plain(), cache.Path()))
 
 	cfg := e.Env.Flags().Config
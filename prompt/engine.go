@@ -19,6 +19,11 @@ type Engine struct {
 	Config                *config.Config
 	activeSegment         *config.Segment
 	previousActiveSegment *config.Segment
+	scheduler             *scheduler
+	line                  *line
+	blockCycle            *color.Cycle
+	textWidths            map[*config.Segment]int
+	truncation            *truncation
 	rprompt               string
 	Overflow              config.Overflow
 	prompt                strings.Builder
@@ -28,6 +33,12 @@ type Engine struct {
 	Plain                 bool
 }
 
+const (
+	// the columns kept free next to a right block and the right prompt
+	blockBreathingRoom   = 5
+	rpromptBreathingRoom = 30
+)
+
 const (
 	PRIMARY   = "primary"
 	TRANSIENT = "transient"
@@ -54,24 +65,16 @@ func (e *Engine) canWriteRightBlock(length int, rprompt bool) (int, bool) {
 		return 0, false
 	}
 
-	consoleWidth, err := e.Env.TerminalWidth()
-	if err != nil || consoleWidth == 0 {
+	consoleWidth, availableSpace, OK := e.lineSpace()
+	if !OK {
 		return 0, false
 	}
 
-	availableSpace := consoleWidth - e.currentLineLength
-
-	// spanning multiple lines
-	if availableSpace < 0 {
-		overflow := e.currentLineLength % consoleWidth
-		availableSpace = consoleWidth - overflow
-	}
-
 	availableSpace -= length
 
-	promptBreathingRoom := 5
+	promptBreathingRoom := blockBreathingRoom
 	if rprompt {
-		promptBreathingRoom = 30
+		promptBreathingRoom = rpromptBreathingRoom
 	}
 
 	canWrite := availableSpace >= promptBreathingRoom
@@ -84,6 +87,24 @@ func (e *Engine) canWriteRightBlock(length int, rprompt bool) (int, bool) {
 	return availableSpace, canWrite
 }
 
+// lineSpace returns the width of the terminal and the columns left on the current line
+func (e *Engine) lineSpace() (int, int, bool) {
+	consoleWidth, err := e.Env.TerminalWidth()
+	if err != nil || consoleWidth == 0 {
+		return 0, 0, false
+	}
+
+	availableSpace := consoleWidth - e.currentLineLength
+
+	// spanning multiple lines
+	if availableSpace < 0 {
+		overflow := e.currentLineLength % consoleWidth
+		availableSpace = consoleWidth - overflow
+	}
+
+	return consoleWidth, availableSpace, true
+}
+
 func (e *Engine) pwd() {
 	// only print when relevant
 	if len(e.Config.PWD) == 0 {
@@ -156,6 +177,9 @@ func (e *Engine) getTitleTemplateText() string {
 }
 
 func (e *Engine) renderBlock(block *config.Block, cancelNewline bool) bool {
+	// the block is written again using the same colors when it needs to shrink
+	e.blockCycle = cycle
+
 	text, length := e.writeBlockSegments(block)
 
 	// do not print anything when we don't have any text unless forced
@@ -175,8 +199,16 @@ func (e *Engine) renderBlock(block *config.Block, cancelNewline bool) bool {
 	switch block.Type {
 	case config.Prompt:
 		if block.Alignment == config.Left {
+			e.addToLine(block)
 			e.currentLineLength += length
 			e.write(text)
+
+			for e.lineOverflows() {
+				if !e.dropSegment(block, nil) {
+					break
+				}
+			}
+
 			return true
 		}
 
@@ -186,13 +218,28 @@ func (e *Engine) renderBlock(block *config.Block, cancelNewline bool) bool {
 
 		space, OK := e.canWriteRightBlock(length, false)
 
+		// drop segments on both sides of the line until the right block fits
+		for !OK && e.dropSegment(nil, block) {
+			text, length = e.rewriteBlock(block)
+			space, OK = e.canWriteRightBlock(length, false)
+		}
+
+		if !OK && block.Overflow == config.Truncate {
+			text, length, space, OK = e.truncateBlock(block, length)
+		}
+
+		if length == 0 && !block.Force {
+			return true
+		}
+
 		// we can't print the right block as there's not enough room available
 		if !OK {
 			e.Overflow = block.Overflow
 			switch block.Overflow {
 			case config.Break:
 				e.writeNewline()
-			case config.Hide:
+			case config.Hide, config.Truncate:
+				// a block that can't be truncated enough is hidden,
 				// make sure to fill if needed
 				if padText, OK := e.shouldFill(block.Filler, space+length); OK {
 					e.write(padText)
@@ -259,7 +306,7 @@ func (e *Engine) renderActiveSegment() {
 
 	switch e.activeSegment.ResolveStyle() {
 	case config.Plain, config.Powerline:
-		terminal.Write(color.Background, color.Foreground, e.activeSegment.Text())
+		e.writeText()
 	case config.Diamond:
 		background := color.Transparent
 
@@ -268,10 +315,10 @@ func (e *Engine) renderActiveSegment() {
 		}
 
 		terminal.Write(background, color.Background, e.activeSegment.LeadingDiamond)
-		terminal.Write(color.Background, color.Foreground, e.activeSegment.Text())
+		e.writeText()
 	case config.Accordion:
 		if e.activeSegment.Enabled {
-			terminal.Write(color.Background, color.Foreground, e.activeSegment.Text())
+			e.writeText()
 		}
 	}
 
@@ -280,6 +327,30 @@ func (e *Engine) renderActiveSegment() {
 	terminal.SetParentColors(e.previousActiveSegment.ResolveBackground(), e.previousActiveSegment.ResolveForeground())
 }
 
+// writeText writes the text of the active segment and measures how many columns it takes,
+// it's shortened to the width truncateBlock set for the segment
+func (e *Engine) writeText() {
+	segment := e.activeSegment
+	start := terminal.Len()
+
+	width, truncated := e.truncation.width(segment)
+	if truncated {
+		terminal.Truncate(width, e.textWidths[segment], e.truncation.fromStart)
+	}
+
+	terminal.Write(color.Background, color.Foreground, segment.Text())
+
+	if truncated {
+		return
+	}
+
+	if e.textWidths == nil {
+		e.textWidths = make(map[*config.Segment]int)
+	}
+
+	e.textWidths[segment] = terminal.Len() - start
+}
+
 func (e *Engine) writeSeparator(final bool) {
 	if e.activeSegment == nil {
 		return
@@ -442,12 +513,24 @@ func (e *Engine) rectifyTerminalWidth(diff int) {
 // given configuration options, and is ready to print any
 // of the prompt components.
 func New(flags *runtime.Flags) *Engine {
-	flags.Config = config.Path(flags.Config)
+	// the shell that requested the prompt might not be ours, see omp serve
+	flags.Config = config.PathFrom(flags.Config, runtime.GetenvFrom(flags.Environ))
 	cfg := config.Load(flags.Config, flags.Shell)
 
+	flags.CacheBackend = cfg.CacheBackend
+
+	// refreshing an async segment happens in the background, it doesn't hold up a prompt
+	if !flags.Refresh {
+		flags.RenderBudget = cfg.RenderBudget
+	}
+
 	env := &runtime.Terminal{}
 	env.Init(flags)
 
+	if overlay, err := env.HasParentFilePath(config.OverlayFileName, false); err == nil && !overlay.IsDir {
+		cfg.Overlay(overlay.Path)
+	}
+
 	template.Init(env, cfg.Var)
 
 	flags.HasExtra = cfg.DebugPrompt != nil ||
@@ -456,7 +539,7 @@ func New(flags *runtime.Flags) *Engine {
 		cfg.ValidLine != nil ||
 		cfg.ErrorLine != nil
 
-	terminal.Init(env.Shell())
+	terminal.Init(env.Shell(), env.Getenv)
 	terminal.BackgroundColor = cfg.TerminalBackground.ResolveTemplate()
 	terminal.Colors = cfg.MakeColors(env)
 	terminal.Plain = flags.Plain
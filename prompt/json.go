@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"github.com/LNKLEO/OMP/color"
+	"github.com/LNKLEO/OMP/config"
+	"github.com/LNKLEO/OMP/terminal"
+
+	json "github.com/goccy/go-json"
+)
+
+const (
+	JSONFormat = "json"
+)
+
+// Output is the structured representation of a rendered prompt
+type Output struct {
+	Type   string         `json:"type"`
+	Prompt string         `json:"prompt"`
+	Blocks []*BlockOutput `json:"blocks,omitempty"`
+}
+
+type BlockOutput struct {
+	Type      config.BlockType      `json:"type"`
+	Alignment config.BlockAlignment `json:"alignment"`
+	Segments  []*SegmentOutput      `json:"segments"`
+}
+
+type SegmentOutput struct {
+	Data       any                 `json:"data,omitempty"`
+	Name       string              `json:"name"`
+	Type       config.SegmentType  `json:"type"`
+	Style      config.SegmentStyle `json:"style,omitempty"`
+	Foreground color.Ansi          `json:"foreground,omitempty"`
+	Background color.Ansi          `json:"background,omitempty"`
+	Text       string              `json:"text,omitempty"`
+	Duration   float64             `json:"duration_ms"`
+	Enabled    bool                `json:"enabled"`
+}
+
+// JSON describes the prompt that was rendered for promptType, and the blocks and segments
+// that were used to create it. It needs to be called after rendering so the values
+// match exactly with what the prompt shows.
+func (e *Engine) JSON(promptType, prompt string) (string, error) {
+	output := &Output{
+		Type:   promptType,
+		Prompt: prompt,
+	}
+
+	switch promptType {
+	case PRIMARY, RIGHT:
+		for _, block := range e.Config.Blocks {
+			if promptType == RIGHT && block.Type != config.RPrompt {
+				continue
+			}
+
+			output.Blocks = append(output.Blocks, e.blockOutput(block))
+		}
+	case TOOLTIP:
+		output.Blocks = append(output.Blocks, e.blockOutput(&config.Block{
+			Type:      config.Prompt,
+			Alignment: config.Right,
+			Segments:  e.Config.Tooltips,
+		}))
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func (e *Engine) blockOutput(block *config.Block) *BlockOutput {
+	blockOutput := &BlockOutput{
+		Type:      block.Type,
+		Alignment: block.Alignment,
+		Segments:  make([]*SegmentOutput, 0, len(block.Segments)),
+	}
+
+	for _, segment := range block.Segments {
+		blockOutput.Segments = append(blockOutput.Segments, e.segmentOutput(segment))
+	}
+
+	return blockOutput
+}
+
+func (e *Engine) segmentOutput(segment *config.Segment) *SegmentOutput {
+	segmentOutput := &SegmentOutput{
+		Name:     segment.Name(),
+		Type:     segment.Type,
+		Duration: float64(segment.Duration.Microseconds()) / 1000,
+		Enabled:  segment.Enabled,
+	}
+
+	writer := segment.Writer()
+	if writer == nil {
+		return segmentOutput
+	}
+
+	segmentOutput.Data = writer
+	segmentOutput.Style = segment.ResolveStyle()
+	segmentOutput.Foreground = resolveColor(segment.Foreground)
+	segmentOutput.Background = resolveColor(segment.Background)
+
+	if segment.Enabled {
+		segmentOutput.Text = writer.Text()
+	}
+
+	return segmentOutput
+}
+
+func resolveColor(value color.Ansi) color.Ansi {
+	if terminal.Colors == nil {
+		return value
+	}
+
+	resolved, err := terminal.Colors.Resolve(value)
+	if err != nil {
+		return value
+	}
+
+	return resolved
+}
@@ -2,8 +2,10 @@ package prompt
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/LNKLEO/OMP/cache"
 	"github.com/LNKLEO/OMP/config"
 	"github.com/LNKLEO/OMP/shell"
 	"github.com/LNKLEO/OMP/terminal"
@@ -46,6 +48,18 @@ func (e *Engine) writePrimaryPrompt(needsPrimaryRPrompt bool) {
 	cycle = &e.Config.Cycle
 	var cancelNewline, didRender bool
 
+	// execute the segments of all blocks at once, a block can reference segments from the ones that follow
+	blocks := make([]*config.Block, 0, len(e.Config.Blocks))
+	for _, block := range e.Config.Blocks {
+		if block.Type == config.RPrompt && !needsPrimaryRPrompt {
+			continue
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	e.scheduler = newScheduler(e.Env, blocks...)
+
 	for i, block := range e.Config.Blocks {
 		// do not print a leading newline when we're at the first row and the prompt is cleared
 		if i == 0 {
@@ -92,17 +106,30 @@ func (e *Engine) needsPrimaryRightPrompt() bool {
 	switch e.Env.Shell() {
 	case shell.PWSH, shell.PWSH5, shell.GENERIC, shell.ZSH:
 		return true
+	case shell.BASH:
+		// without ble.sh, the init script asks for the right prompt to be part of the primary one
+		return e.Env.Flags().InlineRPrompt
 	default:
 		return false
 	}
 }
 
 func (e *Engine) writePrimaryRightPrompt() {
+	if e.Env.Shell() == shell.BASH {
+		// the right prompt is drawn again next to this line when the terminal gets resized
+		e.Env.Session().Set(cache.PROMPTLINECACHE, strconv.Itoa(e.currentLineLength), cache.ONEDAY)
+	}
+
 	space, OK := e.canWriteRightBlock(e.rpromptLength, true)
 	if !OK {
 		return
 	}
 
+	if e.Env.Shell() == shell.BASH {
+		e.write(e.bashRPrompt())
+		return
+	}
+
 	e.write(terminal.SaveCursorPosition())
 	e.write(strings.Repeat(" ", space))
 	e.write(e.rprompt)
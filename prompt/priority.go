@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"github.com/LNKLEO/OMP/color"
+	"github.com/LNKLEO/OMP/config"
+	"github.com/LNKLEO/OMP/log"
+)
+
+// line holds the left blocks written on the current line, so the line can be
+// written again after dropping segments to make it fit the terminal
+type line struct {
+	cycle  *color.Cycle
+	blocks []*config.Block
+	// start is the length of the prompt before the line
+	start int
+}
+
+// addToLine keeps track of the left block that's being rendered
+func (e *Engine) addToLine(block *config.Block) {
+	if e.currentLineLength == 0 || e.line == nil {
+		e.line = &line{
+			start: e.prompt.Len(),
+			cycle: e.blockCycle,
+		}
+	}
+
+	e.line.blocks = append(e.line.blocks, block)
+}
+
+// lineOverflows tells whether the left blocks on the current line are wider than the terminal
+func (e *Engine) lineOverflows() bool {
+	consoleWidth, err := e.Env.TerminalWidth()
+	if err != nil || consoleWidth == 0 {
+		return false
+	}
+
+	return e.currentLineLength > consoleWidth
+}
+
+// dropSegment disables the segment with the lowest priority on the current line, or in the right block,
+// and writes the left blocks of the line again. It returns false when there's no segment left to drop.
+//
+// Only segments with a priority can be dropped, when two segments have
+// the same priority the one closest to the end of the line goes first.
+func (e *Engine) dropSegment(current, right *config.Block) bool {
+	// a right block can be the first block on the line
+	onLine := e.line != nil && e.currentLineLength != 0
+
+	var blocks []*config.Block
+	if onLine {
+		blocks = append(blocks, e.line.blocks...)
+	}
+
+	if right != nil {
+		blocks = append(blocks, right)
+	}
+
+	var lowest *config.Segment
+
+	for _, block := range blocks {
+		for _, segment := range block.Segments {
+			if !segment.Enabled || segment.Priority <= 0 {
+				continue
+			}
+
+			if lowest == nil || segment.Priority <= lowest.Priority {
+				lowest = segment
+			}
+		}
+	}
+
+	if lowest == nil {
+		return false
+	}
+
+	log.Debugf("dropping segment %s with priority %d to fit the terminal width", lowest.Name(), lowest.Priority)
+
+	lowest.Enabled = false
+
+	if onLine {
+		e.rewriteLine(current)
+	}
+
+	return true
+}
+
+// rewriteLine replaces the current line in the prompt, current is the block that's being rendered
+func (e *Engine) rewriteLine(current *config.Block) {
+	prompt := e.prompt.String()
+	e.prompt.Reset()
+	e.prompt.WriteString(prompt[:e.line.start])
+
+	e.currentLineLength = 0
+	cycle = e.line.cycle
+
+	for _, block := range e.line.blocks {
+		text, length := e.writeBlock(block)
+		e.currentLineLength += length
+		e.write(text)
+
+		// the block that's being rendered applies the patch itself
+		if block != current {
+			e.applyPowerShellBleedPatch()
+		}
+	}
+
+	// a right block continues with the colors after the line
+	e.blockCycle = cycle
+}
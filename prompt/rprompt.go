@@ -1,7 +1,13 @@
 package prompt
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/LNKLEO/OMP/cache"
 	"github.com/LNKLEO/OMP/config"
+	"github.com/LNKLEO/OMP/shell"
+	"github.com/LNKLEO/OMP/terminal"
 )
 
 func (e *Engine) RPrompt() string {
@@ -29,5 +35,73 @@ func (e *Engine) RPrompt() string {
 
 	e.rpromptLength = length
 
-	return text
+	if e.Env.Shell() != shell.BASH || !e.Env.Flags().InlineRPrompt {
+		return text
+	}
+
+	// redraw the right prompt on the line of the cursor, the terminal got resized.
+	// The input follows the last line of the prompt, the cursor is at least at its end.
+	e.rprompt = text
+	e.currentLineLength = max(e.Env.Flags().Column, e.promptLineLength())
+
+	_, OK := e.canWriteRightBlock(length, true)
+
+	return e.redrawBashRPrompt(OK)
+}
+
+// promptLineLength returns the length of the last line of the primary prompt
+func (e *Engine) promptLineLength() int {
+	value, OK := e.Env.Session().Get(cache.PROMPTLINECACHE)
+	if !OK {
+		return 0
+	}
+
+	length, _ := strconv.Atoi(value)
+	return length
+}
+
+// redrawBashRPrompt replaces the right prompt readline drew for the previous width, only
+// the columns at the right edge of the line change so the input remains untouched.
+// It's removed when it no longer fits next to the prompt and the input.
+func (e *Engine) redrawBashRPrompt(fits bool) string {
+	text := terminal.SaveCursorPosition() + terminal.RightAlign(e.rpromptLength) + terminal.ClearLine()
+
+	if fits {
+		text += e.rprompt
+	}
+
+	text += terminal.RestoreCursorPosition()
+
+	return terminal.EscapeText(unescapeBash(text))
+}
+
+// bashRPrompt positions the right prompt at the right edge of the current line and moves the cursor back.
+// Readline must ignore all of it to keep the cursor where it expects it to be, so it's escaped at once.
+// Aligning to the edge instead of padding with spaces keeps it in place when the terminal gets resized.
+func (e *Engine) bashRPrompt() string {
+	text := terminal.SaveCursorPosition() + terminal.RightAlign(e.rpromptLength) + e.rprompt + terminal.RestoreCursorPosition()
+	return terminal.EscapeText(unescapeBash(text))
+}
+
+// unescapeBash removes the \[ and \] markers from text, escaped backslashes are kept as is
+func unescapeBash(text string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			builder.WriteByte(text[i])
+			continue
+		}
+
+		i++
+
+		if text[i] == '[' || text[i] == ']' {
+			continue
+		}
+
+		builder.WriteByte('\\')
+		builder.WriteByte(text[i])
+	}
+
+	return builder.String()
 }
@@ -0,0 +1,214 @@
+package prompt
+
+import (
+	"fmt"
+	runtime_ "runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/LNKLEO/OMP/config"
+	"github.com/LNKLEO/OMP/log"
+	"github.com/LNKLEO/OMP/runtime"
+)
+
+// minWorkers avoids segments waiting on a command or the network from holding up all the others
+const minWorkers = 8
+
+// node is a segment in the dependency graph of the prompt
+type node struct {
+	segment    *config.Segment
+	done       chan struct{}
+	needs      []*node
+	dependents []*node
+	// waiting is the amount of needs that did not execute yet
+	waiting int
+}
+
+// scheduler executes the segments of the prompt on a bounded pool of workers.
+// A segment only executes after the segments it references in its templates,
+// every other segment executes as soon as there's a worker available.
+type scheduler struct {
+	env       runtime.Environment
+	nodes     map[*config.Segment]*node
+	queue     chan *node
+	remaining int
+	mutex     sync.Mutex
+}
+
+func newScheduler(env runtime.Environment, blocks ...*config.Block) *scheduler {
+	s := &scheduler{
+		env:   env,
+		nodes: make(map[*config.Segment]*node),
+	}
+
+	var nodes []*node
+	names := make(map[string][]*node)
+
+	for _, block := range blocks {
+		for _, segment := range block.Segments {
+			if _, ok := s.nodes[segment]; ok {
+				continue
+			}
+
+			segment.ResolveNeeds()
+
+			n := &node{
+				segment: segment,
+				done:    make(chan struct{}),
+			}
+
+			s.nodes[segment] = n
+			nodes = append(nodes, n)
+			names[segment.Name()] = append(names[segment.Name()], n)
+		}
+	}
+
+	// needs that aren't part of the prompt are ignored, there's nothing to wait for
+	for _, n := range nodes {
+		for _, name := range n.segment.Needs {
+			for _, need := range names[name] {
+				if need != n {
+					n.needs = append(n.needs, need)
+				}
+			}
+		}
+	}
+
+	breakCycles(nodes)
+
+	for _, n := range nodes {
+		n.waiting = len(n.needs)
+
+		for _, need := range n.needs {
+			need.dependents = append(need.dependents, n)
+		}
+	}
+
+	s.start(nodes)
+
+	return s
+}
+
+// breakCycles removes the needs that would make segments wait on each other forever
+func breakCycles(nodes []*node) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[*node]int, len(nodes))
+	var path []*node
+
+	var visit func(n *node)
+	visit = func(n *node) {
+		state[n] = visiting
+		path = append(path, n)
+
+		needs := n.needs[:0]
+
+		for _, need := range n.needs {
+			switch state[need] {
+			case visiting:
+				reportCycle(path, need)
+				continue
+			case unvisited:
+				visit(need)
+			}
+
+			needs = append(needs, need)
+		}
+
+		n.needs = needs
+		path = path[:len(path)-1]
+		state[n] = visited
+	}
+
+	for _, n := range nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+}
+
+// reportCycle logs the needs from start to the last segment in path, which needs start again
+func reportCycle(path []*node, start *node) {
+	var names []string
+
+	for i := slices.Index(path, start); i < len(path); i++ {
+		names = append(names, path[i].segment.Name())
+	}
+
+	names = append(names, start.segment.Name())
+
+	log.Error(fmt.Errorf("segment dependency cycle: %s", strings.Join(names, " -> ")))
+}
+
+func (s *scheduler) start(nodes []*node) {
+	s.remaining = len(nodes)
+	if s.remaining == 0 {
+		return
+	}
+
+	// the queue can hold every segment, finishing a segment never blocks on queueing its dependents
+	s.queue = make(chan *node, len(nodes))
+
+	for _, n := range nodes {
+		if n.waiting == 0 {
+			s.queue <- n
+		}
+	}
+
+	workers := min(len(nodes), max(runtime_.NumCPU()*2, minWorkers))
+
+	for range workers {
+		go s.work()
+	}
+}
+
+func (s *scheduler) work() {
+	for n := range s.queue {
+		n.segment.Execute(s.env)
+		s.finish(n)
+	}
+}
+
+func (s *scheduler) finish(n *node) {
+	close(n.done)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, dependent := range n.dependents {
+		dependent.waiting--
+
+		if dependent.waiting == 0 {
+			s.queue <- dependent
+		}
+	}
+
+	s.remaining--
+
+	if s.remaining == 0 {
+		close(s.queue)
+	}
+}
+
+// schedules tells whether the segments of the block are part of the graph
+func (s *scheduler) schedules(block *config.Block) bool {
+	for _, segment := range block.Segments {
+		if _, ok := s.nodes[segment]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// wait blocks until the segment executed, which means the segments it needs did as well
+func (s *scheduler) wait(segment *config.Segment) {
+	if n, ok := s.nodes[segment]; ok {
+		<-n.done
+	}
+}
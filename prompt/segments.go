@@ -1,36 +1,40 @@
 package prompt
 
 import (
-	"runtime"
-	"slices"
-
 	"github.com/LNKLEO/OMP/config"
 	"github.com/LNKLEO/OMP/terminal"
 )
 
-type result struct {
-	segment *config.Segment
-	index   int
-}
-
 func (e *Engine) writeBlockSegments(block *config.Block) (string, int) {
-	length := len(block.Segments)
-
-	if length == 0 {
+	if len(block.Segments) == 0 {
 		return "", 0
 	}
 
-	out := make(chan result, length)
+	if e.scheduler == nil || !e.scheduler.schedules(block) {
+		e.scheduler = newScheduler(e.Env, block)
+	}
+
+	e.writeSegments(block)
+
+	return e.closeBlock(block)
+}
 
-	for i, segment := range block.Segments {
-		go func(segment *config.Segment) {
-			segment.Execute(e.Env)
-			out <- result{segment, i}
-		}(segment)
+// writeBlock writes the segments of the block again, for when segments got dropped after rendering
+func (e *Engine) writeBlock(block *config.Block) (string, int) {
+	for _, segment := range block.Segments {
+		e.writeSegment(block, segment)
 	}
 
-	e.writeSegments(out, block)
+	return e.closeBlock(block)
+}
+
+// rewriteBlock writes the block that's being rendered again, starting from the same colors
+func (e *Engine) rewriteBlock(block *config.Block) (string, int) {
+	cycle = e.blockCycle
+	return e.writeBlock(block)
+}
 
+func (e *Engine) closeBlock(block *config.Block) (string, int) {
 	if e.activeSegment != nil && len(block.TrailingDiamond) > 0 {
 		e.activeSegment.TrailingDiamond = block.TrailingDiamond
 	}
@@ -43,56 +47,19 @@ func (e *Engine) writeBlockSegments(block *config.Block) (string, int) {
 	return terminal.String()
 }
 
-func (e *Engine) writeSegments(out chan result, block *config.Block) {
-	count := len(block.Segments)
-	// store the current index
-	current := 0
-	// keep track of what we already executed
-	executedCount := 0
-	// store the results
-	results := make([]*config.Segment, count)
-	// store the unique names of executed segments
-	executed := make([]string, count)
-	// store the actual redered index
+// writeSegments writes the segments in order, each one as soon as it executed
+func (e *Engine) writeSegments(block *config.Block) {
+	// store the actual rendered index
 	segmentIndex := 0
 
-	for {
-		select {
-		case res := <-out:
-			executedCount++
-
-			finished := executedCount == count
-
-			results[res.index] = res.segment
-
-			name := res.segment.Name()
-			if !slices.Contains(executed, name) {
-				executed = append(executed, name)
-			}
+	for _, segment := range block.Segments {
+		e.scheduler.wait(segment)
 
-			segment := results[current]
-
-			for segment != nil {
-				if !e.canRenderSegment(segment, executed) && !finished {
-					break
-				}
-
-				if segment.Render(segmentIndex) {
-					segmentIndex++
-				}
-
-				e.writeSegment(block, segment)
-
-				if current == count-1 {
-					return
-				}
-
-				current++
-				segment = results[current]
-			}
-		default:
-			runtime.Gosched()
+		if segment.Render(segmentIndex) {
+			segmentIndex++
 		}
+
+		e.writeSegment(block, segment)
 	}
 }
 
@@ -116,15 +83,3 @@ func (e *Engine) writeSegment(block *config.Block, segment *config.Segment) bool
 
 	return true
 }
-
-func (e *Engine) canRenderSegment(segment *config.Segment, executed []string) bool {
-	for _, name := range segment.Needs {
-		if slices.Contains(executed, name) {
-			continue
-		}
-
-		return false
-	}
-
-	return true
-}
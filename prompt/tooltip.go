@@ -44,6 +44,14 @@ func (e *Engine) Tooltip(tip string) string {
 	}
 
 	switch e.Env.Shell() {
+	case shell.BASH:
+		// ble.sh renders the tooltip as its right prompt, only position it ourselves
+		// when the cursor column is known, which means we're drawing it using bind -x
+		if e.Env.Flags().Column == 0 {
+			return text
+		}
+
+		fallthrough
 	case shell.PWSH, shell.PWSH5:
 		e.rprompt = text
 		e.currentLineLength = e.Env.Flags().Column
@@ -0,0 +1,115 @@
+package prompt
+
+import (
+	"slices"
+
+	"github.com/LNKLEO/OMP/config"
+)
+
+const (
+	// keep at least one character next to the ellipsis
+	minTruncatedWidth = 2
+)
+
+// truncation holds the widths truncateBlock shortens the segments to
+type truncation struct {
+	widths    map[*config.Segment]int
+	fromStart bool
+}
+
+func (t *truncation) width(segment *config.Segment) (int, bool) {
+	if t == nil {
+		return 0, false
+	}
+
+	width, OK := t.widths[segment]
+	return width, OK
+}
+
+// truncateBlock shortens the longest segments in the right block so it fits. The overflow follows
+// from the length the block was written with, every segment is shortened by what it needs at once.
+// The block is written as is when it doesn't fit at all.
+func (e *Engine) truncateBlock(block *config.Block, length int) (string, int, int, bool) {
+	var segments []*config.Segment
+	var widths []int
+
+	for _, segment := range block.Segments {
+		if !segment.Enabled {
+			continue
+		}
+
+		segments = append(segments, segment)
+		widths = append(widths, e.textWidths[segment])
+	}
+
+	_, availableSpace, OK := e.lineSpace()
+	overflow := length - (availableSpace - blockBreathingRoom)
+
+	if targets, fits := shrink(widths, overflow); OK && fits {
+		e.truncation = &truncation{
+			widths:    make(map[*config.Segment]int),
+			fromStart: block.TruncateSide == config.TruncateStart,
+		}
+
+		for i, segment := range segments {
+			if targets[i] < widths[i] {
+				e.truncation.widths[segment] = targets[i]
+			}
+		}
+
+		text, length := e.rewriteBlock(block)
+		e.truncation = nil
+
+		if space, OK := e.canWriteRightBlock(length, false); OK {
+			return text, length, space, true
+		}
+	}
+
+	text, length := e.rewriteBlock(block)
+	space, OK := e.canWriteRightBlock(length, false)
+
+	return text, length, space, OK
+}
+
+// shrink lowers the widths, the largest ones first, until their sum dropped by the overflow.
+// It returns false when that's not possible without going below minTruncatedWidth.
+func shrink(widths []int, overflow int) ([]int, bool) {
+	targets := slices.Clone(widths)
+
+	if overflow <= 0 {
+		return targets, true
+	}
+
+	// find the highest level that removes enough columns when every width above it is lowered to it
+	for level := slices.Max(append([]int{0}, widths...)) - 1; level >= minTruncatedWidth; level-- {
+		removed := 0
+
+		for _, width := range widths {
+			removed += max(width-level, 0)
+		}
+
+		if removed < overflow {
+			continue
+		}
+
+		// give back the columns that were removed on top of the overflow
+		extra := removed - overflow
+
+		for i, width := range widths {
+			if width <= level {
+				continue
+			}
+
+			targets[i] = level
+
+			if extra > 0 {
+				targets[i]++
+				extra--
+			}
+		}
+
+		return targets, true
+	}
+
+	return targets, false
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+)
+
+// Detach starts the command in the background without waiting for it to finish,
+// the process isn't bound to ours so it keeps running after we exit.
+// It uses environ, or the environment of our process when it's empty, with env added.
+func Detach(command string, args, environ []string, env ...string) error {
+	if len(environ) == 0 {
+		environ = os.Environ()
+	}
+
+	cmd := exec.Command(command, args...)
+	cmd.Env = append(slices.Clip(environ), env...)
+	cmd.SysProcAttr = detachedProcess()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package cmd
+
+import "syscall"
+
+func detachedProcess() *syscall.SysProcAttr {
+	// start a new session so the shell's job control doesn't wait for or signal the process
+	return &syscall.SysProcAttr{
+		Setsid: true,
+	}
+}
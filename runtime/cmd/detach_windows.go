@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+func detachedProcess() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+		HideWindow:    true,
+	}
+}
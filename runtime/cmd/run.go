@@ -10,10 +10,23 @@ import (
 
 // Run is used to correctly run a command with a timeout.
 func Run(command string, args ...string) (string, error) {
+	return RunContext(context.Background(), command, args...)
+}
+
+// RunContext runs a command with a timeout, it's killed as soon as ctx is done
+func RunContext(ctx context.Context, command string, args ...string) (string, error) {
+	return RunIn(ctx, "", nil, command, args...)
+}
+
+// RunIn runs a command like RunContext, in dir and using env,
+// the working folder and environment of our process are used when they are empty
+func RunIn(ctx context.Context, dir string, env []string, command string, args ...string) (string, error) {
 	// set a timeout of 4 seconds
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*4)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*4)
 	defer cancel()
 	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = dir
+	cmd.Env = env
 	var out bytes.Buffer
 	var err bytes.Buffer
 	cmd.Stdout = &out
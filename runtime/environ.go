@@ -0,0 +1,47 @@
+package runtime
+
+import (
+	"os"
+	"strings"
+)
+
+// parseEnviron maps the key=value pairs of an environment to their values
+func parseEnviron(environ []string) map[string]string {
+	result := make(map[string]string, len(environ))
+
+	for _, variable := range environ {
+		key, value, ok := strings.Cut(variable, "=")
+		// Windows has hidden variables like =C: that start with the separator
+		if !ok || len(key) == 0 {
+			continue
+		}
+
+		result[envKey(key)] = value
+	}
+
+	return result
+}
+
+// GetenvFrom returns a function that reads the variables of the given environment,
+// or our own when there is none
+func GetenvFrom(environ []string) func(string) string {
+	if len(environ) == 0 {
+		return os.Getenv
+	}
+
+	variables := parseEnviron(environ)
+
+	return func(key string) string {
+		return variables[envKey(key)]
+	}
+}
+
+// lookupEnv returns the variable from the environment of the shell that requested the prompt,
+// which is our own unless the prompt is rendered by omp serve
+func (term *Terminal) lookupEnv(key string) string {
+	if term.environ == nil {
+		return os.Getenv(key)
+	}
+
+	return term.environ[envKey(key)]
+}
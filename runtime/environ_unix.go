@@ -0,0 +1,39 @@
+//go:build !windows
+
+package runtime
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func envKey(key string) string {
+	return key
+}
+
+// lookPath is exec.LookPath using the PATH of the shell that requested the prompt
+func (term *Terminal) lookPath(command string) (string, error) {
+	if term.environ == nil || strings.Contains(command, "/") {
+		return exec.LookPath(command)
+	}
+
+	for _, dir := range filepath.SplitList(term.lookupEnv("PATH")) {
+		// relative folders depend on the working folder, exec.LookPath refuses those as well
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+
+		path := filepath.Join(dir, command)
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+			continue
+		}
+
+		return path, nil
+	}
+
+	return "", &exec.Error{Name: command, Err: exec.ErrNotFound}
+}
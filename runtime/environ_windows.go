@@ -0,0 +1,54 @@
+package runtime
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// the environment is case insensitive on Windows
+func envKey(key string) string {
+	return strings.ToUpper(key)
+}
+
+// lookPath is exec.LookPath using the PATH and PATHEXT of the shell that requested the prompt
+func (term *Terminal) lookPath(command string) (string, error) {
+	if term.environ == nil || strings.ContainsAny(command, `:\/`) {
+		return exec.LookPath(command)
+	}
+
+	extensions := []string{".com", ".exe", ".bat", ".cmd"}
+	if pathExt := term.lookupEnv("PATHEXT"); len(pathExt) != 0 {
+		extensions = strings.Split(strings.ToLower(pathExt), ";")
+	}
+
+	candidates := make([]string, 0, len(extensions)+1)
+
+	// a command that already has an extension is tried as is first
+	if len(filepath.Ext(command)) != 0 {
+		candidates = append(candidates, command)
+	}
+
+	for _, extension := range extensions {
+		if len(extension) != 0 {
+			candidates = append(candidates, command+extension)
+		}
+	}
+
+	for _, dir := range filepath.SplitList(term.lookupEnv("PATH")) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+
+		for _, candidate := range candidates {
+			path := filepath.Join(dir, candidate)
+
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+	}
+
+	return "", &exec.Error{Name: command, Err: exec.ErrNotFound}
+}
@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"context"
 	"io"
 	"io/fs"
 
@@ -67,6 +68,7 @@ type Environment interface {
 	Connection() ([]*Connection, error)
 	CursorPosition() (row, col int)
 	SystemInfo() (*SystemInfo, error)
+	Context() context.Context
 }
 
 type Flags struct {
@@ -77,6 +79,7 @@ type Flags struct {
 	ShellVersion  string
 	PWD           string
 	AbsolutePWD   string
+	CacheBackend  cache.Backend
 	Type          string
 	ErrorCode     int
 	PromptCount   int
@@ -85,6 +88,8 @@ type Flags struct {
 	TerminalWidth int
 	ExecutionTime float64
 	JobCount      int
+	AsyncPID      int
+	RenderBudget  int
 	IsPrimary     bool
 	HasExtra      bool
 	Debug         bool
@@ -96,6 +101,15 @@ type Flags struct {
 	Init          bool
 	Migrate       bool
 	Eval          bool
+	Repaint       bool
+	Refresh       bool
+	Bench         bool
+	InlineRPrompt bool
+	// SessionID, Cwd and Environ belong to the shell that requested the prompt,
+	// they are only set when that's another process, see omp serve
+	SessionID string
+	Cwd       string
+	Environ   []string
 }
 
 type CommandError struct {
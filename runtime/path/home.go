@@ -7,7 +7,12 @@ import (
 )
 
 func Home() string {
-	home := os.Getenv("HOME")
+	return HomeFrom(os.Getenv)
+}
+
+// HomeFrom returns the home folder set in the environment getenv reads from
+func HomeFrom(getenv func(string) string) string {
+	home := getenv("HOME")
 	defer func() {
 		log.Debug(home)
 	}()
@@ -17,10 +22,10 @@ func Home() string {
 	}
 
 	// fallback to older implemenations on Windows
-	home = os.Getenv("HOMEDRIVE") + os.Getenv("HOMEPATH")
+	home = getenv("HOMEDRIVE") + getenv("HOMEPATH")
 
 	if len(home) == 0 {
-		home = os.Getenv("USERPROFILE")
+		home = getenv("USERPROFILE")
 	}
 
 	return home
@@ -9,7 +9,6 @@ import (
 	httplib "net/http"
 	"net/http/httputil"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -32,9 +31,12 @@ import (
 type Terminal struct {
 	CmdFlags     *Flags
 	cmdCache     *cache.Command
-	deviceCache  *cache.File
-	sessionCache *cache.File
+	deviceCache  cache.Cache
+	sessionCache cache.Cache
+	ctx          context.Context
+	cancel       context.CancelFunc
 	lsDirMap     maps.Concurrent
+	environ      map[string]string
 	cwd          string
 	host         string
 	networks     []*Connection
@@ -54,26 +56,59 @@ func (term *Terminal) Init(flags *Flags) {
 		log.Debug("plain mode enabled")
 	}
 
-	initCache := func(fileName string) *cache.File {
-		fileCache := &cache.File{}
-		fileCache.Init(filepath.Join(cache.Path(), fileName), term.CmdFlags.SaveCache)
-		return fileCache
+	if len(term.CmdFlags.Environ) != 0 {
+		term.environ = parseEnviron(term.CmdFlags.Environ)
+	}
+
+	if len(term.CmdFlags.SessionID) == 0 {
+		term.CmdFlags.SessionID = cache.SessionID()
+	}
+
+	backend := cache.SelectBackend(term.CmdFlags.CacheBackend, term.lookupEnv)
+	log.Debug("cache backend:", string(backend))
+
+	initCache := func(fileName string) cache.Cache {
+		return newCache(backend, filepath.Join(cache.Path(), fileName), term.CmdFlags.SaveCache)
 	}
 
 	term.deviceCache = initCache(cache.FileName)
-	term.sessionCache = initCache(cache.SessionFileName)
+	term.sessionCache = initCache(cache.SessionFileNameFor(term.CmdFlags.SessionID))
 	term.setPromptCount()
 
 	term.setPwd()
 
-	term.cmdCache = &cache.Command{
-		Commands: maps.NewConcurrent(),
+	term.cmdCache = newCommandCache(term.lookupEnv("PATH"))
+
+	// without a budget the context is never done, segments then skip waiting for it
+	term.ctx = context.Background()
+	if term.CmdFlags.RenderBudget > 0 {
+		term.ctx, term.cancel = context.WithTimeout(context.Background(), time.Duration(term.CmdFlags.RenderBudget)*time.Millisecond)
 	}
 }
 
+// Context is done once the render budget of the prompt is spent,
+// commands, requests and file system probes stop at that point
+func (term *Terminal) Context() context.Context {
+	if term.ctx == nil {
+		return context.Background()
+	}
+
+	return term.ctx
+}
+
+// budgetExceeded avoids touching the file system when there's no time left to use the result
+func (term *Terminal) budgetExceeded() bool {
+	if term.Context().Err() == nil {
+		return false
+	}
+
+	log.Debug("render budget exceeded")
+	return true
+}
+
 func (term *Terminal) Getenv(key string) string {
 	defer log.Trace(time.Now(), key)
-	val := os.Getenv(key)
+	val := term.lookupEnv(key)
 	log.Debug(val)
 	return val
 }
@@ -105,10 +140,13 @@ func (term *Terminal) setPwd() {
 		return
 	}
 
-	dir, err := os.Getwd()
-	if err != nil {
-		log.Error(err)
-		return
+	dir := term.CmdFlags.Cwd
+	if len(dir) == 0 {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			log.Error(err)
+			return
+		}
 	}
 
 	term.cwd = correctPath(dir)
@@ -122,6 +160,10 @@ func (term *Terminal) HasFiles(pattern string) bool {
 func (term *Terminal) HasFilesInDir(dir, pattern string) bool {
 	defer log.Trace(time.Now(), pattern)
 
+	if term.budgetExceeded() {
+		return false
+	}
+
 	fileSystem := os.DirFS(dir)
 	var dirEntries []fs.DirEntry
 
@@ -170,6 +212,10 @@ func (term *Terminal) HasFileInParentDirs(pattern string, depth uint) bool {
 	currentFolder := term.Pwd()
 
 	for c := 0; c < int(depth); c++ {
+		if term.budgetExceeded() {
+			return false
+		}
+
 		if term.HasFilesInDir(currentFolder, pattern) {
 			log.Debug("true")
 			return true
@@ -188,6 +234,10 @@ func (term *Terminal) HasFileInParentDirs(pattern string, depth uint) bool {
 
 func (term *Terminal) HasFolder(folder string) bool {
 	defer log.Trace(time.Now(), folder)
+	if term.budgetExceeded() {
+		return false
+	}
+
 	f, err := os.Stat(folder)
 	if err != nil {
 		log.Debug("false")
@@ -215,6 +265,10 @@ func (term *Terminal) FileContent(file string) string {
 		file = filepath.Join(term.Pwd(), file)
 	}
 
+	if term.budgetExceeded() {
+		return ""
+	}
+
 	content, err := os.ReadFile(file)
 	if err != nil {
 		log.Error(err)
@@ -230,6 +284,10 @@ func (term *Terminal) FileContent(file string) string {
 func (term *Terminal) LsDir(input string) []fs.DirEntry {
 	defer log.Trace(time.Now(), input)
 
+	if term.budgetExceeded() {
+		return nil
+	}
+
 	entries, err := os.ReadDir(input)
 	if err != nil {
 		log.Error(err)
@@ -242,9 +300,9 @@ func (term *Terminal) LsDir(input string) []fs.DirEntry {
 
 func (term *Terminal) User() string {
 	defer log.Trace(time.Now())
-	user := os.Getenv("USER")
+	user := term.lookupEnv("USER")
 	if user == "" {
-		user = os.Getenv("USERNAME")
+		user = term.lookupEnv("USERNAME")
 	}
 	log.Debug(user)
 	return user
@@ -275,7 +333,7 @@ func (term *Terminal) GOOS() string {
 }
 
 func (term *Terminal) Home() string {
-	return path.Home()
+	return path.HomeFrom(term.lookupEnv)
 }
 
 func (term *Terminal) RunCommand(command string, args ...string) (string, error) {
@@ -283,9 +341,14 @@ func (term *Terminal) RunCommand(command string, args ...string) (string, error)
 
 	if cacheCommand, ok := term.cmdCache.Get(command); ok {
 		command = cacheCommand
+	} else if term.environ != nil {
+		// resolve the command using the PATH of the shell instead of ours
+		if cmdPath := term.CommandPath(command); len(cmdPath) != 0 {
+			command = cmdPath
+		}
 	}
 
-	output, err := cmd.Run(command, args...)
+	output, err := cmd.RunIn(term.Context(), term.CmdFlags.Cwd, term.CmdFlags.Environ, command, args...)
 	if err != nil {
 		log.Error(err)
 	}
@@ -311,7 +374,7 @@ func (term *Terminal) CommandPath(command string) string {
 		return cmdPath
 	}
 
-	cmdPath, err := exec.LookPath(command)
+	cmdPath, err := term.lookPath(command)
 	if err == nil {
 		term.cmdCache.Set(command, cmdPath)
 		log.Debug(cmdPath)
@@ -399,7 +462,7 @@ func (term *Terminal) unWrapError(err error) error {
 func (term *Terminal) HTTPRequest(targetURL string, body io.Reader, timeout int, requestModifiers ...http.RequestModifier) ([]byte, error) {
 	defer log.Trace(time.Now(), targetURL)
 
-	ctx, cncl := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
+	ctx, cncl := context.WithTimeout(term.Context(), time.Millisecond*time.Duration(timeout))
 	defer cncl()
 
 	request, err := httplib.NewRequestWithContext(ctx, httplib.MethodGet, targetURL, body)
@@ -454,6 +517,10 @@ func (term *Terminal) HasParentFilePath(parent string, followSymlinks bool) (*Fi
 	}
 
 	for {
+		if err := term.Context().Err(); err != nil {
+			return nil, err
+		}
+
 		fileSystem := os.DirFS(pwd)
 		info, err := fs.Stat(fileSystem, parent)
 		if err == nil {
@@ -498,9 +565,23 @@ func (term *Terminal) Session() cache.Cache {
 
 func (term *Terminal) Close() {
 	defer log.Trace(time.Now())
+
+	if term.cancel != nil {
+		term.cancel()
+	}
+
 	term.clearCacheFiles()
 	term.deviceCache.Close()
 	term.sessionCache.Close()
+
+	// the stats only cover the prompts the user sees, not the benchmark or async refreshes
+	if !term.CmdFlags.SaveCache || term.CmdFlags.Bench || term.CmdFlags.Refresh {
+		return
+	}
+
+	if err := cache.SaveStats(); err != nil {
+		log.Error(err)
+	}
 }
 
 func (term *Terminal) clearCacheFiles() {
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"github.com/LNKLEO/OMP/cache"
+	"github.com/LNKLEO/OMP/maps"
+)
+
+// warm holds the state that is kept between renders, see KeepWarm
+var warm *warmState
+
+type warmState struct {
+	caches   map[string]cache.Cache
+	commands map[string]*cache.Command
+}
+
+// KeepWarm keeps the caches and the resolved command paths in memory between renders,
+// for a process that renders more than one prompt. Renders can't run in parallel.
+func KeepWarm() {
+	warm = &warmState{
+		caches:   make(map[string]cache.Cache),
+		commands: make(map[string]*cache.Command),
+	}
+}
+
+func newCache(backend cache.Backend, cacheFilePath string, persist bool) cache.Cache {
+	if warm == nil {
+		fileCache := cache.New(backend)
+		fileCache.Init(cacheFilePath, persist)
+		return fileCache
+	}
+
+	key := string(backend) + cacheFilePath
+
+	if fileCache, ok := warm.caches[key]; ok {
+		if reusable, ok := fileCache.(cache.Reusable); ok {
+			reusable.Reuse(persist)
+			return fileCache
+		}
+	}
+
+	fileCache := cache.New(backend)
+	fileCache.Init(cacheFilePath, persist)
+	warm.caches[key] = fileCache
+
+	return fileCache
+}
+
+func newCommandCache(path string) *cache.Command {
+	if warm == nil {
+		return &cache.Command{
+			Commands: maps.NewConcurrent(),
+		}
+	}
+
+	// the resolved paths depend on the PATH of the shell that requested the prompt
+	if commands, ok := warm.commands[path]; ok {
+		return commands
+	}
+
+	commands := &cache.Command{
+		Commands: maps.NewConcurrent(),
+	}
+
+	warm.commands[path] = commands
+
+	return commands
+}
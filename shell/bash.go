@@ -15,13 +15,37 @@ func (f Feature) Bash() Code {
 		return unixCursorPositioning
 	case FTCSMarks:
 		return unixFTCSMarks
-	case PromptMark, RPrompt, Git, Azure, LineError, Jobs, Tooltips, Transient:
+	case Jobs:
+		return "_omp_jobs=1"
+	case Daemon:
+		return "_omp_daemon=1"
+	case Tooltips:
+		return "_omp_enable_tooltips"
+	case Transient:
+		return bashRequiresBlesh("the transient prompt") + "\n_omp_enable_transient_prompt"
+	case RPrompt:
+		return "_omp_enable_right_prompt"
+	case LineError:
+		return bashRequiresBlesh("the valid and error line") + "\n_omp_enable_line_error"
+	case Git:
+		return bashUnsupported("posh-git integration")
+	case Azure:
+		return bashUnsupported("Azure PowerShell integration")
+	case PromptMark, Async:
 		fallthrough
 	default:
 		return ""
 	}
 }
 
+func bashUnsupported(feature string) Code {
+	return Code(fmt.Sprintf("# WARNING: %s is not supported in bash", feature))
+}
+
+func bashRequiresBlesh(feature string) Code {
+	return Code(fmt.Sprintf("# WARNING: %s requires ble.sh in bash, it's disabled when ble.sh is not loaded", feature))
+}
+
 func QuotePosixStr(str string) string {
 	if len(str) == 0 {
 		return "''"
@@ -18,7 +18,7 @@ func (f Feature) Cmd() Code {
 		return "ftcs_marks_enabled = true"
 	case Tooltips:
 		return "enable_tooltips()"
-	case PromptMark, Git, Azure, LineError, Jobs, CursorPositioning:
+	case PromptMark, Git, Azure, LineError, Jobs, CursorPositioning, Async, Daemon:
 		fallthrough
 	default:
 		return ""
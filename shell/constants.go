@@ -3,6 +3,8 @@ package shell
 const (
 	ZSH     = "zsh"
 	BASH    = "bash"
+	FISH    = "fish"
+	NU      = "nu"
 	PWSH    = "pwsh"
 	PWSH5   = "powershell"
 	CMD     = "cmd"
@@ -13,6 +13,8 @@ const (
 	PromptMark
 	RPrompt
 	CursorPositioning
+	Async
+	Daemon
 )
 
 type Features []Feature
@@ -30,6 +32,10 @@ func (f Features) Lines(shell string) Lines {
 			code = feature.Zsh()
 		case BASH:
 			code = feature.Bash()
+		case FISH:
+			code = feature.Fish()
+		case NU:
+			code = feature.Nu()
 		case CMD:
 			code = feature.Cmd()
 		}
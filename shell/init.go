@@ -62,7 +62,7 @@ func Init(env runtime.Environment, feats Features) string {
 		executable = quotePwshStr(executable)
 
 		return fmt.Sprintf(command, executable, shell, config, additionalParams)
-	case ZSH, BASH, CMD:
+	case ZSH, BASH, FISH, NU, CMD:
 		return PrintInit(env, feats, nil)
 	default:
 		return fmt.Sprintf(`echo "%s is not supported by OMP"`, shell)
@@ -97,6 +97,16 @@ func PrintInit(env runtime.Environment, features Features, startTime *time.Time)
 		configFile = QuotePosixStr(configFile)
 		sessionID = QuotePosixStr(sessionID)
 		script = bashInit
+	case FISH:
+		executable = quoteFishStr(executable)
+		configFile = quoteFishStr(configFile)
+		sessionID = quoteFishStr(sessionID)
+		script = fishInit
+	case NU:
+		executable = quoteNuStr(executable)
+		configFile = quoteNuStr(configFile)
+		sessionID = quoteNuStr(sessionID)
+		script = nuInit
 	case CMD:
 		executable = escapeLuaStr(executable)
 		configFile = escapeLuaStr(configFile)
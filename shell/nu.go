@@ -0,0 +1,41 @@
+package shell
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed scripts/omp.nu
+var nuInit string
+
+func (f Feature) Nu() Code {
+	switch f {
+	case Transient:
+		return `$env.TRANSIENT_PROMPT_COMMAND = {|| _omp_get_prompt transient }
+$env.TRANSIENT_PROMPT_COMMAND_RIGHT = {|| '' }
+$env.TRANSIENT_PROMPT_INDICATOR = {|| '' }
+$env.TRANSIENT_PROMPT_INDICATOR_VI_INSERT = {|| '' }
+$env.TRANSIENT_PROMPT_INDICATOR_VI_NORMAL = {|| '' }
+$env.TRANSIENT_PROMPT_MULTILINE_INDICATOR = {|| '' }`
+	case Daemon:
+		return "$env._OMP_DAEMON = true"
+	case PromptMark, RPrompt, FTCSMarks, Git, Azure, LineError, Jobs, Tooltips, CursorPositioning, Async:
+		fallthrough
+	default:
+		return ""
+	}
+}
+
+func quoteNuStr(str string) string {
+	if len(str) == 0 {
+		return "''"
+	}
+
+	// single quoted strings in Nushell don't support escaping
+	if !strings.Contains(str, "'") {
+		return fmt.Sprintf("'%s'", str)
+	}
+
+	return fmt.Sprintf(`"%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str))
+}
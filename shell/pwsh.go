@@ -25,6 +25,10 @@ func (f Feature) Pwsh() Code {
 		return "$global:_ompGit = $true"
 	case FTCSMarks:
 		return "$global:_ompFTCSMarks = $true"
+	case Async:
+		return "Enable-OMPAsync"
+	case Daemon:
+		return "$global:_ompDaemon = $true"
 	case PromptMark, RPrompt, CursorPositioning:
 		fallthrough
 	default:
@@ -17,14 +17,25 @@ _omp_execution_time=-1
 _omp_no_status=true
 _omp_status=0
 _omp_pipestatus=0
+_omp_job_count=0
+_omp_tooltip_command=''
+_omp_rprompt=''
 _omp_executable=::OMP::
+_omp_at_prompt=0
+_omp_previous_winch_trap=''
 
 # switches to enable/disable features
 _omp_cursor_positioning=0
 _omp_ftcs_marks=0
+_omp_jobs=0
+_omp_right_prompt=0
+_omp_transient_prompt=0
+_omp_line_error=0
+_omp_inline_right_prompt=0
+_omp_daemon=0
 
 # start timer on command start
-PS0='${_omp_start_time:0:$((_omp_start_time="$(_omp_start_timer)",0))}$(_omp_ftcs_command_start)'
+PS0='${_omp_start_time:0:$((_omp_start_time="$(_omp_start_timer)",0))}${_omp_at_prompt:0:$((_omp_at_prompt=0))}$(_omp_ftcs_command_start)'
 
 # set secondary prompt
 _omp_secondary_prompt=$(
@@ -68,6 +79,27 @@ function set_ompcontext() {
     return
 }
 
+function _omp_get_prompt() {
+    local type=$1
+    local args=("${@:2}")
+
+    "$_omp_executable" print "$type" \
+        --save-cache \
+        --shell=bash \
+        --shell-version="$BASH_VERSION" \
+        --status="$_omp_status" \
+        --pipestatus="${_omp_pipestatus[*]}" \
+        --no-status="$_omp_no_status" \
+        --execution-time="$_omp_execution_time" \
+        --stack-count="$_omp_stack_count" \
+        --job-count="$_omp_job_count" \
+        --terminal-width="${COLUMNS-0}" \
+        --daemon="$_omp_daemon" \
+        --inline-rprompt="$_omp_inline_right_prompt" \
+        "${args[@]}" |
+        tr -d '\0'
+}
+
 function _omp_get_primary() {
     # Avoid unexpected expansions when we're generating the prompt below.
     shopt -u promptvars
@@ -78,20 +110,29 @@ function _omp_get_primary() {
         # Disable in POSIX mode.
         prompt='[NOTICE: OMP prompt is not supported in POSIX mode]\n\u@\h:\w\$ '
     else
-        prompt=$(
-            "$_omp_executable" print primary \
-                --save-cache \
-                --shell=bash \
-                --shell-version="$BASH_VERSION" \
-                --status="$_omp_status" \
-                --pipestatus="${_omp_pipestatus[*]}" \
-                --no-status="$_omp_no_status" \
-                --execution-time="$_omp_execution_time" \
-                --stack-count="$_omp_stack_count" \
-                --terminal-width="${COLUMNS-0}" |
-                tr -d '\0'
-        )
+        prompt=$(_omp_get_prompt primary)
+    fi
+    echo "${prompt@P}"
+}
+
+function _omp_get_final() {
+    # Avoid unexpected expansions when we're generating the prompt below.
+    shopt -u promptvars
+    trap 'shopt -s promptvars' RETURN
+
+    local prompt=''
+    if [[ $_omp_transient_prompt == 1 ]]; then
+        prompt=$(_omp_get_prompt transient)
+    fi
+
+    # The accepted command is still in the ble.sh edit buffer, bash only checks its syntax.
+    # Without the transient prompt, the valid or error line is all that remains of the prompt.
+    if [[ $_omp_line_error == 1 ]]; then
+        local type=valid
+        "$BASH" -n -c "$_ble_edit_str" &>/dev/null || type=error
+        prompt+=$(_omp_get_prompt "$type")
     fi
+
     echo "${prompt@P}"
 }
 
@@ -117,6 +158,11 @@ function _omp_hook() {
 
     _omp_stack_count=$((${#DIRSTACK[@]} - 1))
 
+    if [[ $_omp_jobs == 1 ]]; then
+        local job_pids=($(jobs -p))
+        _omp_job_count=${#job_pids[@]}
+    fi
+
     _omp_execution_time=-1
     if [[ $_omp_start_time ]]; then
         local omp_now=$("$_omp_executable" get millis)
@@ -132,12 +178,21 @@ function _omp_hook() {
     set_ompcontext
     _omp_set_cursor_position
 
+    _omp_tooltip_command=''
+    _omp_rprompt=''
+    if [[ $_omp_right_prompt == 1 ]] && [[ $_omp_inline_right_prompt == 0 ]]; then
+        _omp_rprompt=$(_omp_get_prompt right)
+        _omp_rprompt=${_omp_rprompt@P}
+    fi
+
     PS1='$(_omp_get_primary)'
     PS2='$(_omp_get_secondary)'
 
     # Ensure that command substitution works in a prompt string.
     shopt -s promptvars
 
+    _omp_at_prompt=1
+
     return $_omp_status
 }
 
@@ -154,3 +209,127 @@ function _omp_install_hook() {
 }
 
 _omp_install_hook
+
+# ble.sh (https://github.com/akinomyoga/ble.sh) replaces readline and allows
+# rendering a right and final prompt natively.
+function _omp_has_blesh() {
+    [[ ${BLE_VERSION-} ]]
+}
+
+# The final prompt replaces the prompt once a command is accepted.
+function _omp_enable_transient_prompt() {
+    _omp_has_blesh || return
+    _omp_transient_prompt=1
+    bleopt prompt_ps1_final='$(_omp_get_final)'
+    bleopt prompt_rps1_final=''
+}
+
+function _omp_enable_line_error() {
+    _omp_has_blesh || return
+    _omp_line_error=1
+    bleopt prompt_ps1_final='$(_omp_get_final)'
+}
+
+function _omp_enable_right_prompt() {
+    _omp_right_prompt=1
+
+    if _omp_has_blesh; then
+        bleopt prompt_rps1='$_omp_rprompt'
+        return
+    fi
+
+    # Without ble.sh, the right prompt is part of the primary prompt,
+    # we redraw it when the terminal gets resized.
+    _omp_inline_right_prompt=1
+
+    # Keep the existing WINCH trap, it runs after ours.
+    local previous_trap=$(trap -p WINCH)
+    if [[ -n $previous_trap ]]; then
+        # trap -- '<command>' WINCH
+        eval "set -- $previous_trap"
+        [[ $3 != _omp_redraw_right_prompt ]] && _omp_previous_winch_trap=$3
+    fi
+
+    trap _omp_redraw_right_prompt WINCH
+}
+
+function _omp_redraw_right_prompt() {
+    # Only redraw while readline waits for input, not while a command is running.
+    if [[ $_omp_at_prompt == 1 ]]; then
+        local oldstty=$(stty -g)
+        stty raw -echo min 0
+
+        local row col
+        IFS=';' read -rsdR -p $'\E[6n' row col </dev/tty
+
+        stty "$oldstty"
+
+        # Readline updated COLUMNS and redrew the line, including the right prompt for the previous width.
+        # The prompt saves the cursor, replaces the right edge of the line and restores it, the input stays intact.
+        _omp_print "$(_omp_get_prompt right --column="$((col - 1))")"
+    fi
+
+    [[ -n $_omp_previous_winch_trap ]] && eval "$_omp_previous_winch_trap"
+}
+
+# Prints a prompt to the terminal ourselves, without going through readline.
+function _omp_print() {
+    local text=${1@P}
+
+    # Remove the markers readline uses for non-printing characters.
+    text=${text//[$'\001'$'\002']/}
+
+    printf '%s' "$text" >/dev/tty
+}
+
+function _omp_render_tooltip() {
+    # Insert the space we're bound to.
+    READLINE_LINE="${READLINE_LINE:0:READLINE_POINT} ${READLINE_LINE:READLINE_POINT}"
+    ((READLINE_POINT++))
+
+    # Get the first word of command line as tip.
+    local tooltip_command=${READLINE_LINE#"${READLINE_LINE%%[![:space:]]*}"}
+    tooltip_command=${tooltip_command%%[[:space:]]*}
+
+    # Ignore an empty/repeated tooltip command.
+    if [[ -z $tooltip_command ]] || [[ $tooltip_command == "$_omp_tooltip_command" ]]; then
+        return
+    fi
+
+    _omp_tooltip_command=$tooltip_command
+
+    if _omp_has_blesh; then
+        local tooltip
+        tooltip=$(_omp_get_prompt tooltip --command="$tooltip_command")
+        [[ -z $tooltip ]] && return
+
+        _omp_rprompt=${tooltip@P}
+        bleopt prompt_rps1='$_omp_rprompt'
+        ble/prompt/clear
+        return
+    fi
+
+    # Without ble.sh, we print the tooltip next to the cursor ourselves.
+    local oldstty=$(stty -g)
+    stty raw -echo min 0
+
+    local row col
+    IFS=';' read -rsdR -p $'\E[6n' row col </dev/tty
+
+    stty "$oldstty"
+
+    local tooltip
+    tooltip=$(_omp_get_prompt tooltip --command="$tooltip_command" --column="$((col - 1))")
+    [[ -z $tooltip ]] && return
+
+    _omp_print "$tooltip"
+}
+
+function _omp_enable_tooltips() {
+    if _omp_has_blesh; then
+        ble-bind -x SP _omp_render_tooltip
+        return
+    fi
+
+    bind -x '" ": _omp_render_tooltip'
+}
@@ -22,6 +22,7 @@ $global:_ompJobCount = $false
 $global:_ompFTCSMarks = $false
 $global:_ompGit = $false
 $global:_ompAzure = $false
+$global:_ompDaemon = $false
 $global:_ompExecutable = ::OMP::
 
 New-Module -Name "OMP-Core" -ScriptBlock {
@@ -41,6 +42,7 @@ New-Module -Name "OMP-Core" -ScriptBlock {
     $script:TransientPrompt = $false
     $script:TooltipCommand = ''
     $script:JobCount = 0
+    $script:AsyncRepaint = $false
 
     $env:POWERLINE_COMMAND = "OMP"
     $env:OMP_SHELL = "pwsh"
@@ -260,6 +262,8 @@ New-Module -Name "OMP-Core" -ScriptBlock {
             "--stack-count=$stackCount"
             "--terminal-width=$terminalWidth"
             "--job-count=$script:JobCount"
+            "--daemon=$global:_ompDaemon"
+            if ($script:AsyncRepaint) { "--repaint" }
             if ($Arguments) { $Arguments }
         )
     }
@@ -278,7 +282,7 @@ New-Module -Name "OMP-Core" -ScriptBlock {
 
         Set-OMPPromptType
 
-        if ($script:PromptType -ne 'transient') {
+        if ($script:PromptType -ne 'transient' -and !$script:AsyncRepaint) {
             Update-OMPErrorCode
         }
 
@@ -289,6 +293,7 @@ New-Module -Name "OMP-Core" -ScriptBlock {
         $env:OMP_CURSOR_COLUMN = $Host.UI.RawUI.CursorPosition.X + 1
 
         $output = Get-OMPPrompt $script:PromptType
+        $script:AsyncRepaint = $false
         # make sure PSReadLine knows if we have a multiline prompt
         Set-PSReadLineOption -ExtraPromptLineCount (($output | Measure-Object -Line).Lines - 1)
 
@@ -404,6 +409,25 @@ New-Module -Name "OMP-Core" -ScriptBlock {
         Set-PSReadLineOption -PromptText $validLine, $errorLine
     }
 
+    function Enable-OMPAsync {
+        if ($script:ConstrainedLanguageMode) {
+            return
+        }
+
+        # a background process writes this file when it refreshed an async segment
+        $cachePath = (Invoke-Utf8OMP @("cache", "path")).Trim()
+        $script:AsyncWatcher = [System.IO.FileSystemWatcher]::new($cachePath, "omp.cache.$env:OMP_SESSION_ID.async")
+        $script:AsyncWatcher.EnableRaisingEvents = $true
+
+        $repaint = {
+            & (Get-Module -Name "OMP-Core") { $script:AsyncRepaint = $true }
+            [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+        }
+
+        $null = Register-ObjectEvent -InputObject $script:AsyncWatcher -EventName Created -SourceIdentifier 'OMPAsyncCreated' -Action $repaint
+        $null = Register-ObjectEvent -InputObject $script:AsyncWatcher -EventName Changed -SourceIdentifier 'OMPAsyncChanged' -Action $repaint
+    }
+
     # perform cleanup on removal so a new initialization in current session works
     if (!$script:ConstrainedLanguageMode) {
         $ExecutionContext.SessionState.Module.OnRemove += {
@@ -424,6 +448,12 @@ New-Module -Name "OMP-Core" -ScriptBlock {
             if ((Get-PSReadLineKeyHandler Ctrl+c).Function -eq 'OMPCtrlCKeyHandler') {
                 Set-PSReadLineKeyHandler Ctrl+c -Function CopyOrCancelLine
             }
+
+            if ($script:AsyncWatcher) {
+                Unregister-Event -SourceIdentifier 'OMPAsyncCreated' -ErrorAction Ignore
+                Unregister-Event -SourceIdentifier 'OMPAsyncChanged' -ErrorAction Ignore
+                $script:AsyncWatcher.Dispose()
+            }
         }
     }
 
@@ -432,6 +462,7 @@ New-Module -Name "OMP-Core" -ScriptBlock {
         "Enable-OMPTooltips"
         "Enable-OMPTransientPrompt"
         "Enable-OMPLineError"
+        "Enable-OMPAsync"
         "prompt"
     )
 } | Import-Module -Global
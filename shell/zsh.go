@@ -17,6 +17,10 @@ func (f Feature) Zsh() Code {
 		return "_omp_create_widget zle-line-init _omp_zle-line-init"
 	case FTCSMarks:
 		return unixFTCSMarks
+	case Async:
+		return "enable_ompasync"
+	case Daemon:
+		return "_omp_daemon=1"
 	case PromptMark, RPrompt, Git, Azure, LineError, Jobs:
 		fallthrough
 	default:
@@ -27,6 +27,41 @@ func (c *context) init(t *Text) {
 
 var renderPool sync.Pool
 
+// compiled holds the parsed templates when they are kept warm, see KeepWarm
+var compiled *compiledTemplates
+
+type compiledTemplates struct {
+	funcs     template.FuncMap
+	templates map[string]*template.Template
+	sync.Mutex
+}
+
+// KeepWarm keeps the parsed templates in memory, for a process that renders more than one prompt
+func KeepWarm() {
+	compiled = &compiledTemplates{
+		funcs:     funcMap(),
+		templates: make(map[string]*template.Template),
+	}
+}
+
+func (c *compiledTemplates) parse(text string) (*template.Template, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if tmpl, ok := c.templates[text]; ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.New("cache").Funcs(c.funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	c.templates[text] = tmpl
+
+	return tmpl, nil
+}
+
 type renderer struct {
 	template *template.Template
 	context  *context
@@ -48,7 +83,15 @@ func (t *renderer) release() {
 }
 
 func (t *renderer) execute(text *Text) (string, error) {
-	tmpl, err := t.template.Parse(text.Template)
+	var tmpl *template.Template
+	var err error
+
+	if compiled != nil {
+		tmpl, err = compiled.parse(text.Template)
+	} else {
+		tmpl, err = t.template.Parse(text.Template)
+	}
+
 	if err != nil {
 		log.Error(err)
 		return "", errors.New(InvalidTemplate)
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 	"unicode"
 
@@ -242,3 +243,14 @@ func (f *fields) hasField(field string) bool {
 	_, ok := f.values[field]
 	return ok
 }
+
+// Validate parses the template without rendering it,
+// returning the parse error when the template is invalid
+func Validate(text string) error {
+	if !strings.Contains(text, "{{") || !strings.Contains(text, "}}") {
+		return nil
+	}
+
+	_, err := template.New("validate").Funcs(funcMap()).Parse(text)
+	return err
+}
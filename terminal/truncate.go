@@ -0,0 +1,80 @@
+package terminal
+
+import (
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	ellipsis = "…"
+)
+
+// truncation shortens the text passed to the next Write, see Truncate
+type truncation struct {
+	// the columns of the text that are written, the others are removed
+	start, end int
+	// position is the amount of columns of the text passed so far
+	position  int
+	shortened bool
+}
+
+var cut *truncation
+
+// Truncate shortens the text passed to the next Write to the given width, an ellipsis replaces
+// the columns removed from the start or the end. The total width of the text is the amount
+// of columns it takes when written as is, the columns are counted like Len does.
+// Color overrides and hyperlinks remain intact.
+func Truncate(width, total int, fromStart bool) {
+	if total <= width {
+		cut = nil
+		return
+	}
+
+	available := max(width-runewidth.StringWidth(ellipsis), 0)
+
+	cut = &truncation{
+		end: available,
+	}
+
+	if fromStart {
+		cut.start = total - available
+		cut.end = total
+	}
+}
+
+// keep returns whether the rune of the given width is written, and writes
+// the ellipsis in front of it when the columns before it were removed
+func (t *truncation) keep(width int) bool {
+	position := t.position
+	t.position += width
+
+	if position >= t.start && position+width <= t.end {
+		if t.start != 0 {
+			t.writeEllipsis()
+		}
+
+		return true
+	}
+
+	if t.start == 0 {
+		t.writeEllipsis()
+	}
+
+	return false
+}
+
+func (t *truncation) writeEllipsis() {
+	if t.shortened {
+		return
+	}
+
+	t.shortened = true
+	builder.WriteString(ellipsis)
+	length += runewidth.StringWidth(ellipsis)
+}
+
+// finish ends the truncation once the text is written,
+// the ellipsis is the only thing left when nothing remains of the text
+func (t *truncation) finish() {
+	t.writeEllipsis()
+	cut = nil
+}
@@ -2,7 +2,6 @@ package terminal
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/LNKLEO/OMP/color"
@@ -96,9 +95,10 @@ const (
 	Unknown         = "Unknown"
 )
 
-func Init(sh string) {
+// Init prepares the writer for the given shell, getenv reads the environment of the shell
+func Init(sh string, getenv func(string) string) {
 	Shell = sh
-	Program = getTerminalName()
+	Program = getTerminalName(getenv)
 
 	log.Debug("terminal program:", Program)
 	log.Debug("terminal shell:", Shell)
@@ -108,13 +108,13 @@ func Init(sh string) {
 	formats = shell.GetFormats(Shell)
 }
 
-func getTerminalName() string {
-	Program = os.Getenv("TERM_PROGRAM")
+func getTerminalName(getenv func(string) string) string {
+	Program = getenv("TERM_PROGRAM")
 	if len(Program) != 0 {
 		return Program
 	}
 
-	wtSession := os.Getenv("WT_SESSION")
+	wtSession := getenv("WT_SESSION")
 	if len(wtSession) != 0 {
 		return WindowsTerminal
 	}
@@ -180,6 +180,15 @@ func ClearAfter() string {
 	return formats.ClearLine + formats.ClearBelow
 }
 
+// ClearLine clears the current line from the cursor to the end
+func ClearLine() string {
+	if Plain {
+		return ""
+	}
+
+	return formats.ClearLine
+}
+
 func FormatTitle(title string) string {
 	switch Shell {
 	// These shells don't support setting the console title.
@@ -234,6 +243,14 @@ func CommandFinished(code int, ignore bool) string {
 	return fmt.Sprintf(formats.Escape, mark)
 }
 
+// RightAlign moves the cursor to where text of the given width needs to start to end at the right edge,
+// regardless of the current column or the terminal width
+func RightAlign(width int) string {
+	right := fmt.Sprintf(formats.Escape, "\x1b[1000C")
+	left := fmt.Sprintf(formats.Left, width)
+	return right + left
+}
+
 func LineBreak() string {
 	cr := fmt.Sprintf(formats.Left, 1000)
 	lf := fmt.Sprintf(formats.Linechange, 1, "B")
@@ -258,6 +275,7 @@ func StopProgress() string {
 
 func Write(background, foreground color.Ansi, text string) {
 	if len(text) == 0 {
+		cut = nil
 		return
 	}
 
@@ -347,6 +365,10 @@ func Write(background, foreground color.Ansi, text string) {
 		write(s)
 	}
 
+	if cut != nil {
+		cut.finish()
+	}
+
 	// reset colors
 	writeEscapedAnsiString(resetStyle.End)
 
@@ -362,6 +384,7 @@ func String() (string, int) {
 	defer func() {
 		length = 0
 		builder.Reset()
+		cut = nil
 
 		isTransparent = false
 		isInvisible = false
@@ -392,8 +415,13 @@ func write(s rune) {
 		return
 	}
 
+	width := runewidth.RuneWidth(s)
+	if cut != nil && !cut.keep(width) {
+		return
+	}
+
 	// UNSOLVABLE: When "Interactive" is true, the prompt length calculation in Bash/Zsh can be wrong, since the final string expansion is done by shells.
-	length += runewidth.RuneWidth(s)
+	length += width
 	// length += utf8.RuneCountInString(string(s))
 
 	if !Interactive && !Plain {